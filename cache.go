@@ -0,0 +1,234 @@
+package eclint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+	"github.com/go-logr/logr"
+)
+
+// Cache remembers the errors found in the files so the unchanged ones aren't
+// linted again.
+//
+// The entries are keyed on a hash of the content of the file, its resolved
+// definition, the rules, and the version of eclint. Hence, editing a file,
+// an .editorconfig file, or upgrading eclint invalidates them.
+type Cache struct {
+	dir     string
+	version string
+}
+
+// cacheEntry is the stored form of a validation error, the JSON one of the
+// ValidationError being meant for the humans.
+type cacheEntry struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message"`
+	Line     []byte `json:"line"`
+	Index    int    `json:"index"`
+	Position int    `json:"position"`
+	Offset   int    `json:"offset"`
+}
+
+// NewCache creates the directory holding the entries of the cache.
+func NewCache(dir string, version string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create the cache directory %s: %w", dir, err)
+	}
+
+	return &Cache{
+		dir:     dir,
+		version: version,
+	}, nil
+}
+
+// LintWithDefinition is like the LintWithDefinition function, replaying the
+// errors of the cache when the file was already linted.
+func (c *Cache) LintWithDefinition(ctx context.Context, d *editorconfig.Definition, filename string) []error {
+	log := logr.FromContextOrDiscard(ctx)
+
+	key, err := c.key(ctx, d, filename)
+	if err != nil {
+		log.V(3).Info("cannot compute the cache key", "filename", filename, "err", err)
+
+		return LintWithDefinition(ctx, d, filename)
+	}
+
+	if errs, ok := c.get(key, filename); ok {
+		log.V(3).Info("cache hit", "filename", filename, "key", key)
+
+		return errs
+	}
+
+	errs := LintWithDefinition(ctx, d, filename)
+
+	if err := c.put(key, errs); err != nil {
+		log.Error(err, "cannot write to the cache", "filename", filename)
+	}
+
+	return errs
+}
+
+// key hashes everything the errors of the file depend on.
+func (c *Cache) key(ctx context.Context, d *editorconfig.Definition, filename string) (string, error) {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "eclint %s\n", c.version)
+
+	names := make([]string, 0, len(d.Raw))
+	for name := range d.Raw {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(h, "%s=%s\n", name, d.Raw[name])
+	}
+
+	fmt.Fprintf(h, "max-line-size:%d\n", maxLineSizeFromContext(ctx))
+
+	properties := propertiesFromContext(ctx)
+	overridden := make([]string, 0, len(properties))
+
+	for name := range properties {
+		overridden = append(overridden, name)
+	}
+
+	sort.Strings(overridden)
+
+	for _, name := range overridden {
+		fmt.Fprintf(h, "set %s=%s\n", name, properties[name])
+	}
+
+	fmt.Fprintf(h, "decompress:%t\n", decompressFromContext(ctx))
+	fmt.Fprintf(h, "eol-consistency:%t\n", eolConsistencyFromContext(ctx))
+	fmt.Fprintf(h, "indent-unit:%t\n", indentUnitFromContext(ctx))
+	fmt.Fprintf(h, "infer-indent:%t\n", inferIndentFromContext(ctx))
+
+	if prefix, ok := strictConfigFromContext(ctx); ok {
+		fmt.Fprintf(h, "strict-config:%s\n", prefix)
+	}
+
+	rules := rulesFromContext(ctx)
+	for _, rule := range Rules() {
+		fmt.Fprintf(h, "%s:%t:%s\n", rule, rules.enabled(rule), severity(ctx, rule))
+	}
+
+	fp, err := os.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("cannot open %s: %w", filename, err)
+	}
+
+	defer fp.Close()
+
+	if _, err := io.Copy(h, fp); err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", filename, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// path is where the entry of the key is stored.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// get returns the errors stored for the key, if any.
+func (c *Cache) get(key string, filename string) ([]error, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entries []cacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, false
+	}
+
+	if len(entries) == 0 {
+		return nil, true
+	}
+
+	errs := make([]error, len(entries))
+
+	for i, e := range entries {
+		errs[i] = ValidationError{
+			Rule:     e.Rule,
+			Severity: e.Severity,
+			Message:  e.Message,
+			Filename: filename,
+			Line:     e.Line,
+			Index:    e.Index,
+			Position: e.Position,
+			Offset:   e.Offset,
+		}
+	}
+
+	return errs, true
+}
+
+// put stores the errors of the key, unless some aren't validation errors,
+// e.g. a file that cannot be read.
+func (c *Cache) put(key string, errs []error) error {
+	entries := make([]cacheEntry, 0, len(errs))
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); !ok {
+			return nil
+		}
+
+		entries = append(entries, cacheEntry{
+			Rule:     ve.Rule,
+			Severity: ve.Severity,
+			Message:  ve.Message,
+			Line:     ve.Line,
+			Index:    ve.Index,
+			Position: ve.Position,
+			Offset:   ve.Offset,
+		})
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("cannot encode the errors: %w", err)
+	}
+
+	// The workers may write the same entry, the rename keeps it whole.
+	fp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create the cache entry: %w", err)
+	}
+
+	defer os.Remove(fp.Name())
+
+	if _, err := fp.Write(data); err != nil {
+		fp.Close()
+
+		return fmt.Errorf("cannot write the cache entry: %w", err)
+	}
+
+	if err := fp.Close(); err != nil {
+		return fmt.Errorf("cannot write the cache entry: %w", err)
+	}
+
+	if err := os.Rename(fp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("cannot write the cache entry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package eclint_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"gitlab.com/greut/eclint"
+)
+
+func TestCache(t *testing.T) {
+	hits := 0
+	log := funcr.New(func(_, args string) {
+		if strings.Contains(args, "cache hit") {
+			hits++
+		}
+	}, funcr.Options{Verbosity: 3})
+
+	ctx := logr.NewContext(context.TODO(), log)
+	dir := t.TempDir()
+
+	filename := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(filename, []byte("hello \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	trim := true
+	def := &editorconfig.Definition{
+		TrimTrailingWhitespace: &trim,
+		Raw:                    map[string]string{"trim_trailing_whitespace": "true"},
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+
+	cache, err := eclint.NewCache(cacheDir, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []string{"miss", "hit"} {
+		errs := cache.LintWithDefinition(ctx, def, filename)
+		if len(errs) != 1 {
+			t.Fatalf("%s: one error was expected, got %v", step, errs)
+		}
+
+		var ve eclint.ValidationError
+		if ok := errors.As(errs[0], &ve); !ok {
+			t.Fatalf("%s: a validation error was expected, got %s", step, errs[0])
+		}
+
+		if ve.Rule != eclint.RuleTrailingWhitespace || ve.Filename != filename || ve.Position != 5 {
+			t.Errorf("%s: a trailing whitespace error was expected, got %s", step, ve)
+		}
+	}
+
+	if hits != 1 {
+		t.Errorf("one cache hit was expected, got %d", hits)
+	}
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("one cache entry was expected, got %d", len(entries))
+	}
+
+	// A new definition, version, or content is another entry.
+	def.Raw["indent_style"] = eclint.SpaceValue
+	def.IndentStyle = eclint.SpaceValue
+
+	_ = cache.LintWithDefinition(ctx, def, filename)
+
+	cache, err = eclint.NewCache(cacheDir, "1.2.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = cache.LintWithDefinition(ctx, def, filename)
+
+	if err := os.WriteFile(filename, []byte("hello\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := cache.LintWithDefinition(ctx, def, filename); len(errs) != 0 {
+		t.Errorf("no errors were expected once fixed, got %v", errs)
+	}
+
+	entries, err = os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 4 || hits != 1 {
+		t.Errorf("four cache entries and no new hits were expected, got %d and %d", len(entries), hits)
+	}
+}
+
+func TestCacheInferIndent(t *testing.T) {
+	hits := 0
+	log := funcr.New(func(_, args string) {
+		if strings.Contains(args, "cache hit") {
+			hits++
+		}
+	}, funcr.Options{Verbosity: 3})
+
+	ctx := logr.NewContext(context.TODO(), log)
+	dir := t.TempDir()
+
+	// Mostly indented with tabs, but one line.
+	filename := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(filename, []byte("a\n\tb\n\tc\n\td\n    e\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	def := &editorconfig.Definition{Raw: map[string]string{}}
+
+	cache, err := eclint.NewCache(filepath.Join(dir, "cache"), "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		Name   string
+		Infer  bool
+		Errors int
+	}{
+		{Name: "miss", Infer: false, Errors: 0},
+		{Name: "inferred miss", Infer: true, Errors: 1},
+		{Name: "hit", Infer: false, Errors: 0},
+		{Name: "inferred hit", Infer: true, Errors: 1},
+	} {
+		ctx := ctx
+		if step.Infer {
+			ctx = eclint.WithIndentInference(ctx)
+		}
+
+		if errs := cache.LintWithDefinition(ctx, def, filename); len(errs) != step.Errors {
+			t.Errorf("%s: %d errors were expected, got %v", step.Name, step.Errors, errs)
+		}
+	}
+
+	if hits != 2 {
+		t.Errorf("two cache hits were expected, got %d", hits)
+	}
+}
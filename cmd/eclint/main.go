@@ -1,13 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
+	"runtime/debug"
 	"runtime/pprof"
+	"sort"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/editorconfig/editorconfig-core-go/v2"
 	"github.com/go-logr/logr"
@@ -24,11 +31,54 @@ const (
 	overridePrefix = "eclint_"
 )
 
+var (
+	errColor     = errors.New(`color can be "always", "auto", or "never"`)
+	errStdinArgs = errors.New("no paths can be given when reading from stdin")
+	errStdinFix  = errors.New("fixing is not supported when reading from stdin")
+	errMaxErrors = errors.New("max-errors cannot be negative")
+	errMaxLine   = errors.New("max-line-bytes must be positive")
+	errProperty  = errors.New("key=value was expected")
+	errFilesFrom = errors.New("no paths can be given when reading them from a file")
+	errDiffFix   = errors.New("fixing is not supported when filtering on a diff")
+	errDiffStdin = errors.New("the diff and the files cannot both be read from the standard input")
+)
+
 func main() { //nolint:funlen
 	flagVersion := false
 	color := "auto"
 	cpuprofile := ""
 	memprofile := ""
+	stdin := false
+	stdinFilename := "stdin"
+	configFile := ""
+	listFiles := false
+	filesFrom := ""
+	output := ""
+	diffFile := ""
+	noGitIgnore := false
+	rootDir := ""
+	followSymlinks := false
+	includeVCS := false
+	showStats := false
+	showReport := false
+	cacheDir := ""
+	showTimings := 0
+	inferIndent := false
+	indentUnit := false
+	eolConsistency := false
+	strictConfig := false
+	decompress := false
+	showProgress := false
+	useDefaults := false
+	printConfig := ""
+	properties := propertiesFlag{}
+	enableRules := []string{}
+	disableRules := []string{}
+	warnRules := []string{}
+	exitCode := 1
+	maxErrors := 0
+	maxLineBytes := eclint.DefaultMaxLineSize
+	gitTimeout := eclint.DefaultGitTimeout
 
 	// hack to ensure other deferrable are executed beforehand.
 	retcode := 0
@@ -39,9 +89,13 @@ func main() { //nolint:funlen
 
 	defer klog.Flush()
 
+	version = resolveVersion(version)
+
 	opt := &eclint.Option{
 		Stdout:            os.Stdout,
+		Version:           version,
 		ShowErrorQuantity: 10,
+		Jobs:              runtime.NumCPU(),
 		IsTerminal:        term.IsTerminal(int(syscall.Stdout)), //nolint:unconvert
 	}
 
@@ -49,12 +103,38 @@ func main() { //nolint:funlen
 		opt.Stdout = colorable.NewColorableStdout()
 	}
 
+	// The printers and the workers share it.
+	opt.Stdout = eclint.NewSyncWriter(opt.Stdout)
+
 	// Flags
 	klog.InitFlags(nil)
 	flag.BoolVar(&flagVersion, "version", false, "print the version number")
 	flag.StringVar(&color, "color", color, `use color when printing; can be "always", "auto", or "never"`)
+	flag.BoolVar(&opt.NoColors, "no_colors", opt.NoColors, `disable the colors (deprecated, use -color=never)`)
+	flag.StringVar(
+		&opt.Format,
+		"format",
+		eclint.FormatText,
+		`output format; can be "text", "json", "sarif", "github", "gitlab", "checkstyle", "junit", or "null"`,
+	)
+	flag.StringVar(
+		&opt.PathStyle,
+		"path-style",
+		opt.PathStyle,
+		`filenames in the output; can be "relative" or "absolute", as listed when empty`,
+	)
 	flag.BoolVar(&opt.Summary, "summary", opt.Summary, "enable the summary view")
+	flag.BoolVar(&showStats, "stats", showStats, "print the number of errors of each rule after the report")
+	flag.BoolVar(
+		&showReport,
+		"report",
+		showReport,
+		"print a footer counting the files checked and the errors found (the default with -summary)",
+	)
+	flag.BoolVar(&opt.Compact, "compact", opt.Compact, "no blank line between the files (the default when PRE_COMMIT is set)")
+	flag.BoolVar(&opt.Quiet, "quiet", opt.Quiet, "only print the number of errors of the files having some")
 	flag.BoolVar(&opt.FixAllErrors, "fix", opt.FixAllErrors, "enable fixing instead of error reporting")
+	flag.BoolVar(&opt.DryRun, "dry-run", opt.DryRun, "print the fixes as an unified diff instead of applying them")
 	flag.BoolVar(
 		&opt.ShowAllErrors,
 		"show_all_errors",
@@ -67,170 +147,1354 @@ func main() { //nolint:funlen
 		opt.ShowErrorQuantity,
 		"display only the first n errors (0 means all)",
 	)
-	flag.StringVar(&opt.Exclude, "exclude", opt.Exclude, "paths to exclude")
+	flag.IntVar(
+		&opt.ShowErrorQuantity,
+		"show-error-quantity",
+		opt.ShowErrorQuantity,
+		"display only the first n errors (0 means all)",
+	)
+	flag.Var(
+		(*patternsFlag)(&opt.Exclude),
+		"exclude",
+		"paths to exclude; can be repeated or be a comma-separated list of patterns",
+	)
+	flag.Var(
+		(*patternsFlag)(&enableRules),
+		"enable",
+		"only run these rules; can be repeated or be a comma-separated list",
+	)
+	flag.Var(
+		(*patternsFlag)(&disableRules),
+		"disable",
+		"do not run these rules, even if enabled; can be repeated or be a comma-separated list",
+	)
+	flag.Var(
+		(*patternsFlag)(&warnRules),
+		"warn",
+		"report these rules as warnings; can be repeated or be a comma-separated list",
+	)
+	flag.Var(
+		properties,
+		"set",
+		"override the `key=value` property of every file, e.g. indent_size=2; can be repeated",
+	)
+	flag.BoolVar(&opt.FailOnWarning, "fail-on-warning", opt.FailOnWarning, "fail when warnings are found")
+	flag.BoolVar(&opt.ShowRuleNames, "show-rule-names", opt.ShowRuleNames, "append the rule of each error, e.g. [end-of-line]")
+	flag.BoolVar(
+		&inferIndent,
+		"infer-indent",
+		inferIndent,
+		"infer the indentation of the files without an indent_style and report the lines deviating from it",
+	)
+	flag.BoolVar(
+		&strictConfig,
+		"strict-config",
+		strictConfig,
+		"report the unknown properties of the .editorconfig files, e.g. a typo, instead of ignoring them",
+	)
+	flag.BoolVar(
+		&eolConsistency,
+		"check-eol-consistency",
+		eolConsistency,
+		"report the lines not ending like the first one in the files without an end_of_line",
+	)
+	flag.BoolVar(
+		&showProgress,
+		"progress",
+		showProgress,
+		"print the number of files linted on the standard error, when it is a terminal",
+	)
+	flag.BoolVar(
+		&indentUnit,
+		"check-indent-unit",
+		indentUnit,
+		"report the files whose smallest indentation step isn't the indent_size, e.g. 2 spaces for 4",
+	)
+	flag.BoolVar(
+		&useDefaults,
+		"defaults",
+		useDefaults,
+		"enforce lf, a final newline, and no trailing whitespaces on the files without any .editorconfig",
+	)
+	flag.BoolVar(&decompress, "decompress", decompress, "lint the decompressed content of the gzip files")
+	flag.StringVar(
+		&configFile,
+		"config",
+		configFile,
+		"use this EditorConfig `file` for every file instead of searching the .editorconfig files",
+	)
+	flag.StringVar(
+		&rootDir,
+		"root",
+		rootDir,
+		"stop the search of the .editorconfig files at this `dir`, the files must be within it",
+	)
+	flag.BoolVar(
+		&noGitIgnore,
+		"no-gitignore",
+		noGitIgnore,
+		"walk every file on disk, even the ones ignored or not tracked by git",
+	)
+	flag.BoolVar(
+		&followSymlinks,
+		"follow-symlinks",
+		followSymlinks,
+		"walk into the symbolic links to directories, each directory being visited once",
+	)
+	flag.BoolVar(
+		&includeVCS,
+		"include-vcs",
+		includeVCS,
+		"walk into the directories of the version control systems, e.g. .git, when paths are given",
+	)
+	flag.BoolVar(&listFiles, "list-files", listFiles, "print the files that would be linted, then exit")
+	flag.StringVar(
+		&printConfig,
+		"print-config",
+		printConfig,
+		"print the properties enforced on this `file`, derived ones included, then exit",
+	)
+	flag.StringVar(
+		&filesFrom,
+		"files-from",
+		filesFrom,
+		"read the paths to lint from this `file`, one per line (- for the standard input)",
+	)
+	flag.DurationVar(
+		&gitTimeout,
+		"git-timeout",
+		gitTimeout,
+		"walk the directory when git doesn't list the files within this duration, 0 waiting forever",
+	)
+	flag.StringVar(&output, "output", output, "write the errors to this `file` instead of the standard output")
+	flag.StringVar(
+		&diffFile,
+		"diff",
+		diffFile,
+		"only report the errors on the lines added by this unified diff `file` (- for the standard input)",
+	)
+	flag.BoolVar(&stdin, "stdin", stdin, "read the content to lint from the standard input")
+	flag.StringVar(
+		&stdinFilename,
+		"stdin-filename",
+		stdinFilename,
+		"virtual `filename` of the standard input, used to match the EditorConfig sections",
+	)
+	flag.IntVar(&exitCode, "exit-code", exitCode, "exit status when errors are found; 0 disables the failure")
+	flag.IntVar(&maxErrors, "max-errors", maxErrors, "fail only when more than `n` errors are found")
+	flag.IntVar(
+		&maxLineBytes,
+		"max-line-bytes",
+		maxLineBytes,
+		"length of the longest line that can be read, in bytes; the longer ones are reported",
+	)
+	flag.IntVar(&opt.Jobs, "jobs", opt.Jobs, "number of files processed concurrently")
+	flag.IntVar(
+		&showTimings,
+		"timings",
+		showTimings,
+		"print the time spent on the `n` slowest files to stderr after the run; -1 prints all of them",
+	)
+	flag.StringVar(
+		&cacheDir,
+		"cache-dir",
+		cacheDir,
+		"remember the errors of the files in this `dir` to skip the unchanged ones on the next runs",
+	)
 	flag.StringVar(&cpuprofile, "cpuprofile", cpuprofile, "write cpu profile to `file`")
 	flag.StringVar(&memprofile, "memprofile", memprofile, "write mem profile to `file`")
 	flag.Parse()
 
 	if flagVersion {
-		fmt.Fprintf(opt.Stdout, "eclint %s\n", version)
+		printVersion(opt.Stdout, version)
 
 		return
 	}
 
+	if opt.NoColors && !isFlagSet("color") {
+		log.Info("-no_colors is deprecated, use -color=never instead")
+
+		color = "never"
+	}
+
 	switch color {
+	case "auto":
+		// keep the terminal detection
 	case "always":
 		opt.IsTerminal = true
+		opt.NoColors = false
 	case "never":
 		opt.NoColors = true
+	default:
+		log.Error(errColor, "invalid color", "color", color)
+		flag.Usage()
+
+		retcode = 2
+
+		return
+	}
+
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			log.Error(err, "cannot create the output file", "output", output)
+
+			retcode = 2
+
+			return
+		}
+
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Error(err, "cannot write the output file", "output", output)
+
+				retcode = 2
+			}
+		}()
+
+		opt.Stdout = eclint.NewSyncWriter(f)
+
+		// The file isn't a terminal.
+		if color == "auto" {
+			opt.IsTerminal = false
+		}
+	}
+
+	if maxErrors < 0 {
+		log.Error(errMaxErrors, "invalid max-errors", "max-errors", maxErrors)
+		flag.Usage()
+
+		retcode = 2
+
+		return
+	}
+
+	if maxLineBytes < 1 {
+		log.Error(errMaxLine, "invalid max-line-bytes", "max-line-bytes", maxLineBytes)
+		flag.Usage()
+
+		retcode = 2
+
+		return
+	}
+
+	if opt.DryRun {
+		opt.FixAllErrors = true
 	}
 
 	if opt.Summary {
 		opt.ShowAllErrors = true
+
+		if !isFlagSet("report") {
+			showReport = true
+		}
 	}
 
 	if opt.ShowAllErrors {
 		opt.ShowErrorQuantity = 0
 	}
 
-	if opt.Exclude != "" {
-		_, err := editorconfig.FnmatchCase(opt.Exclude, "dummy")
+	for _, pattern := range opt.Exclude {
+		_, err := editorconfig.FnmatchCase(pattern, "dummy")
 		if err != nil {
-			log.Error(err, "exclude pattern failure", "exclude", opt.Exclude)
+			log.Error(err, "exclude pattern failure", "exclude", pattern)
 			flag.Usage()
 
+			retcode = 2
+
 			return
 		}
 	}
 
-	if cpuprofile != "" {
-		f, err := os.Create(cpuprofile)
-		if err != nil {
-			log.Error(err, "could not create CPU profile", "cpuprofile", cpuprofile)
+	if filesFrom != "" && (flag.NArg() > 0 || stdin) {
+		log.Error(errFilesFrom, "invalid arguments", "args", flag.Args(), "stdin", stdin)
+		flag.Usage()
 
-			retcode = 1
+		retcode = 2
+
+		return
+	}
+
+	if diffFile != "" && opt.FixAllErrors {
+		log.Error(errDiffFix, "invalid arguments")
+		flag.Usage()
+
+		retcode = 2
+
+		return
+	}
+
+	if diffFile == "-" && (stdin || filesFrom == "-") {
+		log.Error(errDiffStdin, "invalid arguments")
+		flag.Usage()
+
+		retcode = 2
+
+		return
+	}
+
+	if stdin {
+		if flag.NArg() > 0 {
+			log.Error(errStdinArgs, "invalid arguments", "args", flag.Args())
+			flag.Usage()
+
+			retcode = 2
 
 			return
 		}
 
-		defer f.Close()
+		if opt.FixAllErrors {
+			log.Error(errStdinFix, "invalid arguments")
+			flag.Usage()
 
-		if err := pprof.StartCPUProfile(f); err != nil {
-			log.Error(err, "could not start CPU profile")
+			retcode = 2
+
+			return
 		}
 	}
 
-	ctx := logr.NewContext(context.Background(), log)
+	ctx, err := eclint.WithRules(logr.NewContext(context.Background(), log), enableRules, disableRules)
+	if err != nil {
+		log.Error(err, "invalid rules", "rules", eclint.Rules())
+		flag.Usage()
+
+		retcode = 2
+
+		return
+	}
 
-	c, err := processArgs(ctx, opt, flag.Args())
+	ctx, err = eclint.WithWarnings(ctx, warnRules)
 	if err != nil {
-		log.Error(err, "linting failure")
+		log.Error(err, "invalid warning rules", "rules", eclint.Rules())
+		flag.Usage()
 
 		retcode = 2
 
 		return
 	}
 
-	if memprofile != "" {
-		f, err := os.Create(memprofile)
+	if inferIndent {
+		ctx = eclint.WithIndentInference(ctx)
+	}
+
+	if indentUnit {
+		ctx = eclint.WithIndentUnitCheck(ctx)
+	}
+
+	if eolConsistency {
+		ctx = eclint.WithEndOfLineConsistency(ctx)
+	}
+
+	if strictConfig {
+		ctx = eclint.WithStrictConfig(ctx, overridePrefix)
+	}
+
+	if decompress {
+		ctx = eclint.WithDecompression(ctx)
+	}
+
+	if len(properties) > 0 {
+		ctx = eclint.WithProperties(ctx, properties)
+	}
+
+	if gitTimeout != eclint.DefaultGitTimeout {
+		ctx = eclint.WithGitTimeout(ctx, gitTimeout)
+	}
+
+	if maxLineBytes != eclint.DefaultMaxLineSize {
+		ctx = eclint.WithMaxLineSize(ctx, maxLineBytes)
+	}
+
+	if diffFile != "" {
+		changes, err := readDiff(diffFile)
 		if err != nil {
-			log.Error(err, "could not create memory profile", "memprofile", memprofile)
+			log.Error(err, "cannot read the diff", "diff", diffFile)
+
+			retcode = 2
+
+			return
 		}
-		defer f.Close()
 
-		runtime.GC() // get up-to-date statistics
+		opt.Changes = changes
+	}
 
-		if err := pprof.WriteHeapProfile(f); err != nil {
-			log.Error(err, "could not write memory profile")
+	args := flag.Args()
+	list := func(ctx context.Context) (<-chan string, <-chan error) {
+		return eclint.ListFilesContext(ctx, args...)
+	}
+
+	if noGitIgnore || ((followSymlinks || includeVCS) && len(args) > 0) {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+
+		walkOptions := eclint.WalkOptions{
+			GitIgnore:      !noGitIgnore,
+			FollowSymlinks: followSymlinks,
+			IncludeVCS:     includeVCS,
+		}
+
+		list = func(ctx context.Context) (<-chan string, <-chan error) {
+			return eclint.WalkWithOptionsContext(ctx, walkOptions, args...)
 		}
 	}
 
-	if cpuprofile != "" {
-		pprof.StopCPUProfile()
+	if filesFrom != "" {
+		r := io.Reader(os.Stdin)
+
+		if filesFrom != "-" {
+			f, err := os.Open(filesFrom)
+			if err != nil {
+				log.Error(err, "cannot open the list of files", "files-from", filesFrom)
+
+				retcode = 2
+
+				return
+			}
+
+			defer f.Close()
+
+			r = f
+		}
+
+		list = func(ctx context.Context) (<-chan string, <-chan error) {
+			return eclint.ReadFilesContext(ctx, r)
+		}
 	}
 
-	if c > 0 {
-		log.V(1).Info("some errors were found.", "count", c)
+	if listFiles {
+		if err := printFiles(ctx, opt, list); err != nil {
+			log.Error(err, "listing files failure")
+
+			retcode = 2
+		}
 
-		retcode = 1
+		return
 	}
-}
 
-func processArgs(ctx context.Context, opt *eclint.Option, args []string) (int, error) { //nolint:funlen,gocognit
-	log := logr.FromContextOrDiscard(ctx)
-	c := 0
+	if !isFlagSet("format") && os.Getenv("GITHUB_ACTIONS") == "true" {
+		opt.Format = eclint.FormatGitHub
+	}
 
-	config := &editorconfig.Config{
-		Parser: editorconfig.NewCachedParser(),
+	// The pre-commit framework sets it when running the hooks.
+	if !isFlagSet("compact") && os.Getenv("PRE_COMMIT") != "" {
+		opt.Compact = true
 	}
 
-	fileChan, errChan := eclint.ListFilesContext(ctx, args...)
+	printer, err := eclint.NewPrinter(opt)
+	if err != nil {
+		log.Error(err, "output format failure", "format", opt.Format)
+		flag.Usage()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return 0, ctx.Err()
+		retcode = 2
 
-		case err, ok := <-errChan:
-			if ok {
-				log.Error(err, "cannot list files")
+		return
+	}
 
-				return 0, err
-			}
+	stats := make(eclint.Stats)
+	if showStats {
+		printer = &statsPrinter{Printer: printer, stats: stats}
+	}
 
-		case filename, ok := <-fileChan:
-			if !ok {
-				return c, nil
-			}
+	// The footer would break the machine-readable formats.
+	var report *eclint.Report
+	if showReport && opt.Format == eclint.FormatText {
+		report = &eclint.Report{}
+		printer = &reportPrinter{Printer: printer, report: report}
+	}
 
-			log := log.WithValues("filename", filename)
+	configPrinter := &configErrorPrinter{Printer: printer}
+	printer = configPrinter
 
-			// Skip excluded files
-			if opt.Exclude != "" {
-				ok, err := editorconfig.FnmatchCase(opt.Exclude, filename)
-				if err != nil {
-					log.Error(err, "exclude pattern failure", "exclude", opt.Exclude)
+	// The diffs of a dry run and the stats would break the machine-readable formats too.
+	sideOutput := opt.Stdout
+	if opt.Format != eclint.FormatText {
+		sideOutput = os.Stderr
+	}
 
-					return 0, err
-				}
+	var loader definitionLoader
 
-				if ok {
-					continue
-				}
-			}
+	defaults, err := eclint.LoadEnvConfigFile()
+	if err != nil {
+		log.Error(err, "cannot load the default configuration file", eclint.EnvConfigFile, os.Getenv(eclint.EnvConfigFile))
 
-			def, err := config.Load(filename)
-			if err != nil {
-				log.Error(err, "cannot open file")
+		retcode = 2
 
-				return 0, err
-			}
+		return
+	}
 
-			err = eclint.OverrideDefinitionUsingPrefix(def, overridePrefix)
-			if err != nil {
-				log.Error(err, "overriding the definition failed", "prefix", overridePrefix)
+	loader, err = newSourceLoader(rootDir, defaults)
+	if err != nil {
+		log.Error(err, "cannot use the root directory", "root", rootDir)
 
-				return 0, err
-			}
+		retcode = 2
 
-			// Linting vs Fixing
-			if !opt.FixAllErrors {
-				errs := eclint.LintWithDefinition(ctx, def, filename)
-				c += len(errs)
+		return
+	}
 
-				if err := eclint.PrintErrors(ctx, opt, filename, errs); err != nil {
-					log.Error(err, "print errors failure")
+	if configFile != "" {
+		loader, err = eclint.LoadConfigFile(configFile)
+		if err != nil {
+			log.Error(err, "cannot load the configuration file", "config", configFile)
 
-					return 0, err
-				}
-			} else {
-				err := eclint.FixWithDefinition(ctx, def, filename)
-				if err != nil {
-					log.Error(err, "fixing errors failure")
+			retcode = 2
 
-					return 0, err
-				}
-			}
+			return
+		}
+	}
+
+	if useDefaults {
+		log.V(1).Info("the files without any .editorconfig use the defaults", "properties", eclint.DefaultProperties())
+
+		loader = &fallbackLoader{definitionLoader: loader, properties: eclint.DefaultProperties()}
+	}
+
+	if printConfig != "" {
+		if err := printDefinition(ctx, opt, loader, properties, printConfig); err != nil {
+			log.Error(err, "cannot print the configuration", "print-config", printConfig)
+
+			retcode = 2
+		}
+
+		return
+	}
+
+	var cache *eclint.Cache
+
+	if cacheDir != "" {
+		cache, err = eclint.NewCache(cacheDir, version)
+		if err != nil {
+			log.Error(err, "cannot use the cache directory", "cache-dir", cacheDir)
+
+			retcode = 2
+
+			return
+		}
+	}
+
+	if cpuprofile != "" {
+		f, err := os.Create(cpuprofile)
+		if err != nil {
+			log.Error(err, "could not create CPU profile", "cpuprofile", cpuprofile)
+
+			retcode = 1
+
+			return
+		}
+
+		defer f.Close()
+
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Error(err, "could not start CPU profile")
 		}
 	}
+
+	var timings eclint.Timings
+	if showTimings != 0 {
+		timings = make(eclint.Timings)
+	}
+
+	// The counter is drawn in place, hence only on a terminal.
+	var progress *eclint.Progress
+	if showProgress && term.IsTerminal(int(syscall.Stderr)) { //nolint:unconvert
+		progress = eclint.NewProgress(os.Stderr)
+	}
+
+	var c int
+
+	if stdin {
+		c, err = processStdin(ctx, opt, loader, printer, stdinFilename, os.Stdin)
+	} else {
+		c, err = processArgs(ctx, opt, loader, printer, sideOutput, list, cache, timings, progress)
+	}
+
+	if err != nil {
+		log.Error(err, "linting failure")
+
+		retcode = 2
+
+		return
+	}
+
+	if err := printer.Flush(ctx); err != nil {
+		log.Error(err, "print errors failure")
+
+		retcode = 2
+
+		return
+	}
+
+	if showStats {
+		if err := stats.Print(sideOutput); err != nil {
+			log.Error(err, "print stats failure")
+
+			retcode = 2
+
+			return
+		}
+	}
+
+	if report != nil {
+		if err := report.Print(opt.Stdout); err != nil {
+			log.Error(err, "print report failure")
+
+			retcode = 2
+
+			return
+		}
+	}
+
+	if timings != nil {
+		if err := timings.Print(os.Stderr, showTimings); err != nil {
+			log.Error(err, "print timings failure")
+
+			retcode = 2
+
+			return
+		}
+	}
+
+	if memprofile != "" {
+		f, err := os.Create(memprofile)
+		if err != nil {
+			log.Error(err, "could not create memory profile", "memprofile", memprofile)
+		}
+		defer f.Close()
+
+		runtime.GC() // get up-to-date statistics
+
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			log.Error(err, "could not write memory profile")
+		}
+	}
+
+	if cpuprofile != "" {
+		pprof.StopCPUProfile()
+	}
+
+	if c > 0 {
+		log.V(1).Info("some errors were found.", "count", c)
+	}
+
+	if c > maxErrors {
+		retcode = exitCode
+	}
+
+	if configPrinter.found {
+		log.V(1).Info("some .editorconfig files are invalid.")
+
+		retcode = 2
+	}
+}
+
+// resolveVersion falls back to the module version embedded by Go when none
+// was set via the ldflags.
+func resolveVersion(version string) string {
+	if version != "dev" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return version
+	}
+
+	return info.Main.Version
+}
+
+// printVersion prints the version, then the build details.
+//
+// The first line, "eclint <version>", is kept stable for the scripts.
+func printVersion(w io.Writer, version string) {
+	fmt.Fprintf(w, "eclint %s\n", version)
+	fmt.Fprintf(w, "go: %s\n", runtime.Version())
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			fmt.Fprintf(w, "commit: %s\n", setting.Value)
+		case "vcs.time":
+			fmt.Fprintf(w, "date: %s\n", setting.Value)
+		case "vcs.modified":
+			if setting.Value == "true" {
+				fmt.Fprintln(w, "modified: true")
+			}
+		}
+	}
+}
+
+// patternsFlag collects the patterns of a repeatable flag.
+//
+// Each value may be a comma-separated list, commas within braces being
+// part of the pattern, e.g. "*.{js,css}".
+type patternsFlag []string
+
+func (p *patternsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patternsFlag) Set(value string) error {
+	depth := 0
+	start := 0
+
+	for i, c := range value {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				p.append(value[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	p.append(value[start:])
+
+	return nil
+}
+
+func (p *patternsFlag) append(pattern string) {
+	if pattern = strings.TrimSpace(pattern); pattern != "" {
+		*p = append(*p, pattern)
+	}
+}
+
+// propertiesFlag collects the key=value properties of a repeatable flag.
+type propertiesFlag map[string]string
+
+func (p propertiesFlag) String() string {
+	properties := make([]string, 0, len(p))
+	for k, v := range p {
+		properties = append(properties, k+"="+v)
+	}
+
+	sort.Strings(properties)
+
+	return strings.Join(properties, ",")
+}
+
+func (p propertiesFlag) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if k = strings.TrimSpace(k); !ok || k == "" {
+		return fmt.Errorf("%w: %q", errProperty, value)
+	}
+
+	p[strings.ToLower(k)] = strings.TrimSpace(v)
+
+	return nil
+}
+
+// isFlagSet tells whether the flag was explicitly given.
+func isFlagSet(name string) bool {
+	found := false
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+
+	return found
+}
+
+// definitionLoader resolves the EditorConfig definition of a file.
+type definitionLoader interface {
+	Load(filename string) (*editorconfig.Definition, error)
+	// Sources returns the EditorConfig files read by the last Load.
+	Sources() []string
+}
+
+// sourceLoader looks up the .editorconfig files, remembering the ones it read.
+//
+// When a root directory is given, the search stops there. When defaults are
+// given, they fill in the properties the .editorconfig files don't set.
+type sourceLoader struct {
+	editorconfig.Config
+	parser   *eclint.SourceParser
+	root     *eclint.RootParser
+	defaults *eclint.ConfigFile
+}
+
+func newSourceLoader(rootDir string, defaults *eclint.ConfigFile) (*sourceLoader, error) {
+	var (
+		parser editorconfig.Parser = editorconfig.NewCachedParser()
+		root   *eclint.RootParser
+	)
+
+	if rootDir != "" {
+		rp, err := eclint.NewRootParser(parser, rootDir)
+		if err != nil {
+			return nil, err
+		}
+
+		parser = rp
+		root = rp
+	}
+
+	sp := eclint.NewSourceParser(parser)
+
+	return &sourceLoader{
+		Config:   editorconfig.Config{Parser: sp},
+		parser:   sp,
+		root:     root,
+		defaults: defaults,
+	}, nil
+}
+
+func (l *sourceLoader) Load(filename string) (*editorconfig.Definition, error) {
+	if l.root != nil && !l.root.Contains(filename) {
+		return nil, fmt.Errorf("%w: %s", eclint.ErrOutsideRoot, filename)
+	}
+
+	def, err := l.Config.Load(filename)
+	if err != nil || l.defaults == nil {
+		return def, err //nolint:wrapcheck
+	}
+
+	if err := l.defaults.MergeInto(def, filename); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return def, nil
+}
+
+func (l *sourceLoader) Sources() []string {
+	sources := l.parser.Sources()
+
+	if l.defaults != nil {
+		sources = append(sources, l.defaults.Sources()...)
+	}
+
+	return sources
+}
+
+// fallbackLoader sets the properties of the files without any EditorConfig files.
+type fallbackLoader struct {
+	definitionLoader
+	properties map[string]string
+	sources    []string
+}
+
+func (l *fallbackLoader) Load(filename string) (*editorconfig.Definition, error) {
+	def, err := l.definitionLoader.Load(filename)
+	l.sources = l.definitionLoader.Sources()
+
+	if err != nil || len(l.sources) > 0 {
+		return def, err //nolint:wrapcheck
+	}
+
+	if err := eclint.OverrideDefinition(def, l.properties); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return def, nil
+}
+
+func (l *fallbackLoader) Sources() []string {
+	sources := l.sources
+	l.sources = nil
+
+	return sources
+}
+
+// loadDefinition resolves the definition of the file, logging where it comes from.
+func loadDefinition(
+	ctx context.Context,
+	loader definitionLoader,
+	filename string,
+) (*editorconfig.Definition, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	def, err := loader.Load(filename)
+	sources := loader.Sources()
+
+	if err != nil {
+		return nil, err
+	}
+
+	log.V(2).Info("definition", "filename", filename, "sources", sources, "properties", def.Raw)
+
+	return def, nil
+}
+
+// printDefinition prints the properties enforced on the file, overrides included.
+func printDefinition(
+	ctx context.Context,
+	opt *eclint.Option,
+	loader definitionLoader,
+	properties map[string]string,
+	filename string,
+) error {
+	d, err := loadDefinition(ctx, loader, filename)
+	if err != nil {
+		return err
+	}
+
+	if err := eclint.OverrideDefinitionUsingPrefix(d, overridePrefix); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if err := eclint.OverrideDefinition(d, properties); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	def, err := eclint.NewDefinition(d)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return def.Print(opt.Stdout) //nolint:wrapcheck
+}
+
+// statsPrinter counts the errors of each rule before handing them to the printer.
+type statsPrinter struct {
+	eclint.Printer
+	stats eclint.Stats
+}
+
+func (p *statsPrinter) Print(ctx context.Context, filename string, errs []error) error {
+	p.stats.Add(errs)
+
+	return p.Printer.Print(ctx, filename, errs)
+}
+
+// reportPrinter tallies the files and their errors before handing them to the printer.
+type reportPrinter struct {
+	eclint.Printer
+	report *eclint.Report
+}
+
+func (p *reportPrinter) Print(ctx context.Context, filename string, errs []error) error {
+	p.report.Add(errs)
+
+	return p.Printer.Print(ctx, filename, errs)
+}
+
+// configErrorPrinter remembers whether an invalid configuration was found before handing the errors to the printer.
+type configErrorPrinter struct {
+	eclint.Printer
+	found bool
+}
+
+func (p *configErrorPrinter) Print(ctx context.Context, filename string, errs []error) error {
+	for _, err := range errs {
+		if errors.Is(err, eclint.ErrConfiguration) {
+			p.found = true
+		}
+	}
+
+	return p.Printer.Print(ctx, filename, errs)
+}
+
+// fileLister lists the files to process.
+type fileLister func(ctx context.Context) (<-chan string, <-chan error)
+
+// job is a file waiting to be processed by a worker.
+type job struct {
+	filename string
+	def      *editorconfig.Definition
+	done     chan<- result
+}
+
+// result is the outcome of a job, or of a failure to create one.
+type result struct {
+	filename string
+	errs     []error
+	output   []byte
+	elapsed  time.Duration
+	err      error
+}
+
+func processArgs(
+	ctx context.Context,
+	opt *eclint.Option,
+	loader definitionLoader,
+	printer eclint.Printer,
+	diffOutput io.Writer,
+	list fileLister,
+	cache *eclint.Cache,
+	timings eclint.Timings,
+	progress *eclint.Progress,
+) (int, error) {
+	// The counter is cleared when done, or failing.
+	defer func() {
+		if err := progress.Hide(); err != nil {
+			logr.FromContextOrDiscard(ctx).Error(err, "progress failure")
+		}
+	}()
+
+	c := 0
+
+	err := runJobs(
+		ctx,
+		opt.Jobs,
+		func(ctx context.Context, jobs chan<- job, pending chan<- (<-chan result)) {
+			dispatch(ctx, opt, loader, list, jobs, pending, progress)
+		},
+		func(ctx context.Context, j job) result {
+			start := time.Now()
+			errs, output := processFile(ctx, opt, cache, j.def, j.filename)
+
+			return result{filename: j.filename, errs: errs, output: output, elapsed: time.Since(start)}
+		},
+		func(r result) error {
+			if timings != nil {
+				timings[r.filename] = r.elapsed
+			}
+
+			// The output may share the terminal of the counter.
+			if len(r.output) > 0 || len(r.errs) > 0 {
+				if err := progress.Hide(); err != nil {
+					return err
+				}
+			}
+
+			if _, err := diffOutput.Write(r.output); err != nil {
+				return fmt.Errorf("cannot write output: %w", err)
+			}
+
+			if opt.Changes != nil {
+				r.errs = opt.Changes.Filter(r.filename, r.errs)
+			}
+
+			c += countErrors(opt, r.errs)
+
+			if err := printer.Print(ctx, r.filename, r.errs); err != nil {
+				log := logr.FromContextOrDiscard(ctx)
+				log.Error(err, "print errors failure", "filename", r.filename)
+
+				return err
+			}
+
+			return progress.Advance()
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return c, nil
+}
+
+// runJobs processes the jobs sent by dispatch on the workers, handing their
+// results to handle in the order they were dispatched.
+//
+// It stops at the first failure, of dispatch or of handle, or once the
+// context is done.
+func runJobs(
+	ctx context.Context,
+	workers int,
+	dispatch func(ctx context.Context, jobs chan<- job, pending chan<- (<-chan result)),
+	process func(ctx context.Context, j job) result,
+	handle func(r result) error,
+) error {
+	// The parent context is checked once done, this one stops the dispatching on a failure.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan job, workers)
+	// pending keeps the results in the order the files were listed.
+	pending := make(chan (<-chan result), workers)
+
+	go dispatch(runCtx, jobs, pending)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				j.done <- process(runCtx, j)
+			}
+		}()
+	}
+
+	for done := range pending {
+		var r result
+
+		select {
+		case <-runCtx.Done():
+		case r = <-done:
+		}
+
+		// Nothing is handled once canceled, even the results already done.
+		if err := runCtx.Err(); err != nil {
+			return err
+		}
+
+		if r.err != nil {
+			return r.err
+		}
+
+		if err := handle(r); err != nil {
+			return err
+		}
+	}
+
+	// The dispatching also stops when the run is canceled.
+	return ctx.Err()
+}
+
+// fail hands the error to the results, in place of the ones of the next file.
+func fail(ctx context.Context, pending chan<- (<-chan result), err error) {
+	done := make(chan result, 1)
+	done <- result{err: err}
+
+	select {
+	case pending <- done:
+	case <-ctx.Done():
+	}
+}
+
+// dispatch lists the files and loads their definition before handing them to the workers.
+//
+// The editorconfig parser isn't safe for concurrent use, hence the loader is only used here.
+func dispatch( //nolint:funlen,gocognit,cyclop
+	ctx context.Context,
+	opt *eclint.Option,
+	loader definitionLoader,
+	list fileLister,
+	jobs chan<- job,
+	pending chan<- (<-chan result),
+	progress *eclint.Progress,
+) {
+	defer close(pending)
+	defer close(jobs)
+
+	log := logr.FromContextOrDiscard(ctx)
+
+	fileChan, errChan := list(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+
+				continue
+			}
+
+			log.Error(err, "cannot list files")
+			fail(ctx, pending, err)
+
+			return
+
+		case filename, ok := <-fileChan:
+			if !ok {
+				return
+			}
+
+			log := log.WithValues("filename", filename)
+
+			// Skip excluded files
+			excluded, err := eclint.MatchExclude(opt.Exclude, filename)
+			if err != nil {
+				log.Error(err, "exclude pattern failure")
+				fail(ctx, pending, err)
+
+				return
+			}
+
+			if excluded != "" {
+				log.V(4).Info("skipped excluded file", "exclude", excluded)
+
+				continue
+			}
+
+			def, err := loadDefinition(ctx, loader, filename)
+			if err != nil {
+				log.Error(err, "cannot open file")
+				fail(ctx, pending, err)
+
+				return
+			}
+
+			err = eclint.OverrideDefinitionUsingPrefix(def, overridePrefix)
+			if err != nil {
+				log.Error(err, "overriding the definition failed", "prefix", overridePrefix)
+				fail(ctx, pending, err)
+
+				return
+			}
+
+			done := make(chan result, 1)
+
+			progress.List()
+
+			select {
+			case pending <- done:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- job{filename: filename, def: def, done: done}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// printFiles prints the files that would be linted, one per line.
+//
+// It honors the exclude patterns and skips the directories like the linting does.
+func printFiles(ctx context.Context, opt *eclint.Option, list fileLister) error {
+	log := logr.FromContextOrDiscard(ctx)
+
+	fileChan, errChan := list(ctx)
+
+	for {
+		select {
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+
+				continue
+			}
+
+			return err
+
+		case filename, ok := <-fileChan:
+			if !ok {
+				// An error may still be waiting.
+				if errChan != nil {
+					return <-errChan
+				}
+
+				return nil
+			}
+
+			excluded, err := eclint.MatchExclude(opt.Exclude, filename)
+			if err != nil {
+				return err
+			}
+
+			if excluded != "" {
+				log.V(4).Info("skipped excluded file", "filename", filename, "exclude", excluded)
+
+				continue
+			}
+
+			if fi, err := os.Stat(filename); err == nil && fi.IsDir() {
+				continue
+			}
+
+			if _, err := fmt.Fprintln(opt.Stdout, filename); err != nil {
+				return fmt.Errorf("cannot write output: %w", err)
+			}
+		}
+	}
+}
+
+// processFile lints or fixes the file, the diff of a dry run is returned as the output.
+//
+// When a cache is given, the linting of the unchanged files is skipped.
+func processFile(
+	ctx context.Context,
+	opt *eclint.Option,
+	cache *eclint.Cache,
+	def *editorconfig.Definition,
+	filename string,
+) ([]error, []byte) {
+	switch {
+	case opt.DryRun:
+		buf := bytes.NewBuffer(nil)
+		errs := eclint.DiffWithDefinition(ctx, def, filename, buf)
+
+		return errs, buf.Bytes()
+	case opt.FixAllErrors:
+		return eclint.FixWithDefinition(ctx, def, filename), nil
+	case cache != nil:
+		return cache.LintWithDefinition(ctx, def, filename), nil
+	default:
+		return eclint.LintWithDefinition(ctx, def, filename), nil
+	}
+}
+
+func processStdin(
+	ctx context.Context,
+	opt *eclint.Option,
+	loader definitionLoader,
+	printer eclint.Printer,
+	filename string,
+	stdin io.Reader,
+) (int, error) {
+	log := logr.FromContextOrDiscard(ctx).WithValues("filename", filename)
+
+	buf, err := io.ReadAll(stdin)
+	if err != nil {
+		log.Error(err, "cannot read stdin")
+
+		return 0, err
+	}
+
+	def, err := loadDefinition(ctx, loader, filename)
+	if err != nil {
+		log.Error(err, "cannot load the definition")
+
+		return 0, err
+	}
+
+	err = eclint.OverrideDefinitionUsingPrefix(def, overridePrefix)
+	if err != nil {
+		log.Error(err, "overriding the definition failed", "prefix", overridePrefix)
+
+		return 0, err
+	}
+
+	errs := eclint.LintReaderWithDefinition(ctx, def, filename, bytes.NewReader(buf), int64(len(buf)))
+
+	if opt.Changes != nil {
+		errs = opt.Changes.Filter(filename, errs)
+	}
+
+	if err := printer.Print(ctx, filename, errs); err != nil {
+		log.Error(err, "print errors failure")
+
+		return 0, err
+	}
+
+	return countErrors(opt, errs), nil
+}
+
+// countErrors counts the errors failing the run, the warnings only when asked to.
+func countErrors(opt *eclint.Option, errs []error) int {
+	c := 0
+
+	for _, err := range errs {
+		if err != nil && (opt.FailOnWarning || eclint.Severity(err) != eclint.SeverityWarning) {
+			c++
+		}
+	}
+
+	return c
+}
+
+// readDiff parses the unified diff from the file, or the standard input.
+func readDiff(filename string) (eclint.ChangedLines, error) {
+	if filename == "-" {
+		return eclint.ParseDiff(os.Stdin)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s: %w", filename, err)
+	}
+
+	defer f.Close()
+
+	return eclint.ParseDiff(f)
 }
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"gitlab.com/greut/eclint"
+)
+
+// TestMain runs eclint instead of the tests when the test binary is run by runEclint.
+func TestMain(m *testing.M) {
+	if os.Getenv("ECLINT_TEST_MAIN") != "" {
+		main()
+	}
+
+	os.Exit(m.Run())
+}
+
+// runEclint runs eclint with the arguments from the directory.
+func runEclint(t *testing.T, dir string, args ...string) (string, string, int) {
+	t.Helper()
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(executable, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "ECLINT_TEST_MAIN=1", "EDITORCONFIG=", "GITHUB_ACTIONS=", "PRE_COMMIT=")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !errors.As(err, &exitErr) {
+		t.Fatal(err)
+	}
+
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+// writeFiles creates the files, e.g. an .editorconfig, within a temporary directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestStats(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".editorconfig": "root = true\n\n[*]\ntrim_trailing_whitespace = true\n",
+		"a.txt":         "hello \n",
+	})
+
+	tests := []struct {
+		Name   string
+		Format string
+	}{
+		{
+			Name:   "text",
+			Format: eclint.FormatText,
+		}, {
+			Name:   "json",
+			Format: eclint.FormatJSON,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout, stderr, code := runEclint(t, dir, "-format", tc.Format, "-stats", "a.txt")
+			if code != 1 {
+				t.Fatalf("exit status 1 was expected, got %d: %s", code, stderr)
+			}
+
+			stats := stdout
+			if tc.Format != eclint.FormatText {
+				if !json.Valid([]byte(stdout)) {
+					t.Errorf("a JSON document was expected, got %q", stdout)
+				}
+
+				stats = stderr
+			}
+
+			if !strings.Contains(stats, eclint.RuleTrailingWhitespace) || !strings.Contains(stats, "total") {
+				t.Errorf("the stats were expected, got %q", stats)
+			}
+		})
+	}
+}
+
+func TestPatternsFlagSet(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Values   []string
+		Patterns []string
+	}{
+		{
+			Name:     "single pattern",
+			Values:   []string{"vendor/**"},
+			Patterns: []string{"vendor/**"},
+		}, {
+			Name:     "repeated flag",
+			Values:   []string{"vendor/**", "node_modules/**"},
+			Patterns: []string{"vendor/**", "node_modules/**"},
+		}, {
+			Name:     "comma-separated list",
+			Values:   []string{"vendor/**,node_modules/**,*.min.js"},
+			Patterns: []string{"vendor/**", "node_modules/**", "*.min.js"},
+		}, {
+			Name:     "commas within braces",
+			Values:   []string{"*.{js,css},vendor/**"},
+			Patterns: []string{"*.{js,css}", "vendor/**"},
+		}, {
+			Name:     "nested braces",
+			Values:   []string{"{a,{b,c}}/*.go,*.md"},
+			Patterns: []string{"{a,{b,c}}/*.go", "*.md"},
+		}, {
+			Name:     "trimmed spaces",
+			Values:   []string{" vendor/** , *.md "},
+			Patterns: []string{"vendor/**", "*.md"},
+		}, {
+			Name:     "empty patterns are skipped",
+			Values:   []string{",vendor/**,,", ""},
+			Patterns: []string{"vendor/**"},
+		}, {
+			Name:     "repeated lists",
+			Values:   []string{"vendor/**,*.md", "*.{js,css}"},
+			Patterns: []string{"vendor/**", "*.md", "*.{js,css}"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var p patternsFlag
+
+			for _, value := range tc.Values {
+				if err := p.Set(value); err != nil {
+					t.Fatalf("no errors were expected, got %s", err)
+				}
+			}
+
+			if !cmp.Equal(tc.Patterns, []string(p)) {
+				t.Errorf("diff %s", cmp.Diff(tc.Patterns, []string(p)))
+			}
+		})
+	}
+}
+
+func TestInvalidExclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".editorconfig": "root = true\n\n[*]\ntrim_trailing_whitespace = true\n",
+		"a.txt":         "hello\n",
+	})
+
+	_, stderr, code := runEclint(t, dir, "-exclude", "[a-", "a.txt")
+	if code != 2 {
+		t.Errorf("exit status 2 was expected, got %d", code)
+	}
+
+	if !strings.Contains(stderr, "exclude pattern failure") {
+		t.Errorf("the exclude pattern failure was expected, got %q", stderr)
+	}
+}
+
+// dispatchFiles sends a job per file, then the failure if any.
+func dispatchFiles(filenames []string, failure error) func(context.Context, chan<- job, chan<- (<-chan result)) {
+	return func(ctx context.Context, jobs chan<- job, pending chan<- (<-chan result)) {
+		defer close(pending)
+		defer close(jobs)
+
+		for _, filename := range filenames {
+			done := make(chan result, 1)
+
+			select {
+			case pending <- done:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- job{filename: filename, done: done}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if failure != nil {
+			fail(ctx, pending, failure)
+		}
+	}
+}
+
+func numberedFiles(n int) []string {
+	filenames := make([]string, n)
+	for i := range filenames {
+		filenames[i] = fmt.Sprintf("file%02d", i)
+	}
+
+	return filenames
+}
+
+func TestRunJobsOrder(t *testing.T) {
+	filenames := numberedFiles(20)
+
+	for _, workers := range []int{0, 1, 4, 32} {
+		workers := workers
+
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			t.Parallel()
+
+			delays := make(map[string]time.Duration)
+			for i, filename := range filenames {
+				// The first files are the last ones to be done.
+				delays[filename] = time.Duration(len(filenames)-i) * time.Millisecond
+			}
+
+			handled := make([]string, 0, len(filenames))
+
+			err := runJobs(
+				context.Background(),
+				workers,
+				dispatchFiles(filenames, nil),
+				func(ctx context.Context, j job) result {
+					time.Sleep(delays[j.filename])
+
+					return result{filename: j.filename}
+				},
+				func(r result) error {
+					handled = append(handled, r.filename)
+
+					return nil
+				},
+			)
+			if err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			if !cmp.Equal(filenames, handled) {
+				t.Errorf("diff %s", cmp.Diff(filenames, handled))
+			}
+		})
+	}
+}
+
+func TestRunJobsFailure(t *testing.T) {
+	errFailure := errors.New("failure")
+	filenames := numberedFiles(50)
+
+	tests := []struct {
+		Name     string
+		Dispatch func(context.Context, chan<- job, chan<- (<-chan result))
+		FailOn   string
+		Handled  []string
+	}{
+		{
+			Name:     "dispatch failure",
+			Dispatch: dispatchFiles(filenames[:3], errFailure),
+			Handled:  filenames[:3],
+		}, {
+			Name:     "handle failure",
+			Dispatch: dispatchFiles(filenames, nil),
+			FailOn:   filenames[1],
+			Handled:  filenames[:2],
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			handled := make([]string, 0, len(tc.Handled))
+
+			err := runJobs(
+				context.Background(),
+				4,
+				tc.Dispatch,
+				func(ctx context.Context, j job) result {
+					return result{filename: j.filename}
+				},
+				func(r result) error {
+					handled = append(handled, r.filename)
+					if r.filename == tc.FailOn {
+						return errFailure
+					}
+
+					return nil
+				},
+			)
+			if !errors.Is(err, errFailure) {
+				t.Errorf("failure was expected, got %v", err)
+			}
+
+			if !cmp.Equal(tc.Handled, handled) {
+				t.Errorf("diff %s", cmp.Diff(tc.Handled, handled))
+			}
+		})
+	}
+}
+
+func TestRunJobsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Only the first job cancels the run.
+	started := make(chan struct{}, 1)
+
+	err := runJobs(
+		ctx,
+		4,
+		dispatchFiles(numberedFiles(50), nil),
+		func(ctx context.Context, j job) result {
+			select {
+			case started <- struct{}{}:
+				cancel()
+			default:
+			}
+
+			<-ctx.Done()
+
+			return result{filename: j.filename}
+		},
+		func(r result) error {
+			t.Errorf("no results were expected, got %s", r.filename)
+
+			return nil
+		},
+	)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancellation was expected, got %v", err)
+	}
+}
+
+// fakeLoader returns the same definition for every file, but the failing one.
+type fakeLoader struct {
+	def     editorconfig.Definition
+	failing string
+}
+
+var errLoad = errors.New("cannot load")
+
+func (l *fakeLoader) Load(filename string) (*editorconfig.Definition, error) {
+	if filename == l.failing {
+		return nil, errLoad
+	}
+
+	def := l.def
+
+	return &def, nil
+}
+
+func (l *fakeLoader) Sources() []string {
+	return nil
+}
+
+// recordPrinter remembers the files printed and their errors.
+type recordPrinter struct {
+	filenames []string
+	errs      int
+}
+
+func (p *recordPrinter) Print(_ context.Context, filename string, errs []error) error {
+	p.filenames = append(p.filenames, filename)
+	p.errs += len(errs)
+
+	return nil
+}
+
+func (p *recordPrinter) Flush(_ context.Context) error {
+	return nil
+}
+
+func TestProcessArgs(t *testing.T) {
+	dir := t.TempDir()
+
+	filenames := make([]string, 0, 10)
+
+	for i := 0; i < 10; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf("file%02d.txt", i))
+
+		// The odd files have a trailing whitespace.
+		content := "hello\n"
+		if i%2 == 1 {
+			content = "hello \n"
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		filenames = append(filenames, filename)
+	}
+
+	list := func(ctx context.Context) (<-chan string, <-chan error) {
+		fileChan := make(chan string)
+		errChan := make(chan error)
+
+		go func() {
+			defer close(fileChan)
+			defer close(errChan)
+
+			for _, filename := range filenames {
+				select {
+				case fileChan <- filename:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		return fileChan, errChan
+	}
+
+	trim := true
+	def := editorconfig.Definition{TrimTrailingWhitespace: &trim}
+
+	tests := []struct {
+		Name      string
+		Failing   string
+		Count     int
+		Filenames []string
+	}{
+		{
+			Name:      "all the files",
+			Count:     5,
+			Filenames: filenames,
+		}, {
+			Name:      "failing file",
+			Failing:   filenames[4],
+			Filenames: filenames[:4],
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			opt := &eclint.Option{Jobs: 4, Stdout: io.Discard}
+			printer := &recordPrinter{}
+			timings := make(eclint.Timings)
+
+			c, err := processArgs(
+				context.Background(),
+				opt,
+				&fakeLoader{def: def, failing: tc.Failing},
+				printer,
+				io.Discard,
+				list,
+				nil,
+				timings,
+				nil,
+			)
+
+			if tc.Failing != "" {
+				if !errors.Is(err, errLoad) {
+					t.Errorf("load failure was expected, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			if c != tc.Count {
+				t.Errorf("%d errors were expected, got %d", tc.Count, c)
+			}
+
+			if !cmp.Equal(tc.Filenames, printer.filenames) {
+				t.Errorf("diff %s", cmp.Diff(tc.Filenames, printer.filenames))
+			}
+
+			if tc.Failing == "" && printer.errs != tc.Count {
+				t.Errorf("%d errors were expected to be printed, got %d", tc.Count, printer.errs)
+			}
+
+			if len(timings) != len(tc.Filenames) {
+				t.Errorf("%d timings were expected, got %d", len(tc.Filenames), len(timings))
+			}
+		})
+	}
+}
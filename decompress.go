@@ -0,0 +1,45 @@
+package eclint
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+)
+
+// gzipMagic are the first bytes of a gzip file.
+var gzipMagic = []byte{0x1f, 0x8b} //nolint:gochecknoglobals
+
+// decompressKey is the context key enabling the decompression of the gzip files.
+type decompressKey struct{}
+
+// WithDecompression returns a context where the gzip files are decompressed
+// before being linted, the errors still being reported on the .gz file.
+func WithDecompression(ctx context.Context) context.Context {
+	return context.WithValue(ctx, decompressKey{}, true)
+}
+
+// decompressFromContext tells whether the gzip files have to be decompressed.
+func decompressFromContext(ctx context.Context) bool {
+	decompress, _ := ctx.Value(decompressKey{}).(bool)
+
+	return decompress
+}
+
+// decompress wraps the reader into a gzip one when it starts with the gzip
+// magic bytes, the closer being nil otherwise.
+func decompress(r *bufio.Reader) (*bufio.Reader, io.Closer, error) {
+	magic, err := r.Peek(len(gzipMagic))
+	if err != nil || !bytes.Equal(magic, gzipMagic) {
+		return r, nil, nil //nolint:nilerr
+	}
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decompress: %w", err)
+	}
+
+	return bufio.NewReader(zr), zr, nil
+}
@@ -0,0 +1,50 @@
+package eclint_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+	"gitlab.com/greut/eclint"
+)
+
+func TestLintDecompress(t *testing.T) {
+	filename := "testdata/gzip/a.txt.gz"
+	trim := true
+
+	def := &editorconfig.Definition{
+		TrimTrailingWhitespace: &trim,
+	}
+
+	// The compressed content is skipped as binary.
+	if errs := eclint.LintWithDefinition(context.TODO(), def, filename); len(errs) != 0 {
+		t.Fatalf("no errors were expected, got %v", errs)
+	}
+
+	ctx := eclint.WithDecompression(context.TODO())
+
+	errs := eclint.LintWithDefinition(ctx, def, filename)
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %v", errs)
+	}
+
+	var ve eclint.ValidationError
+	if ok := errors.As(errs[0], &ve); !ok {
+		t.Fatalf("a validation error was expected, got %s", errs[0])
+	}
+
+	if ve.Rule != eclint.RuleTrailingWhitespace || ve.Index != 0 || ve.Filename != filename {
+		t.Errorf("a trailing whitespace error on the first line of %s was expected, got %s", filename, ve)
+	}
+}
+
+func TestLintDecompressPlainFile(t *testing.T) {
+	ctx := eclint.WithDecompression(context.TODO())
+
+	for _, err := range eclint.Lint(ctx, "testdata/simple/simple.txt") {
+		if err != nil {
+			t.Errorf("no errors were expected, got %s", err)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package eclint
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,44 +20,125 @@ var ErrNotImplemented = errors.New("not implemented yet, PRs are welcome")
 // definition contains the fields that aren't native to EditorConfig.Definition.
 type definition struct {
 	editorconfig.Definition
+	// BlockCommentStart, BlockComment, and BlockCommentEnd are the markers of
+	// the current block comment, the first kind of BlockComments otherwise.
 	BlockCommentStart  []byte
 	BlockComment       []byte
 	BlockCommentEnd    []byte
+	BlockComments      []blockCommentMarkers
+	LineComments       [][]byte
 	MaxLength          int
 	TabWidth           int
 	IndentSize         int
 	LastLine           []byte
 	LastIndex          int
+	LastOffset         int
 	InsideBlockComment bool
+	// BlockStringStart and BlockStringEnd delimit the multiline strings, e.g.
+	// Python docstrings, which are excluded from the indentation checks.
+	BlockStringStart  []byte
+	BlockStringEnd    []byte
+	InsideBlockString bool
+	// MaxBlankLines is the maximum of consecutive blank lines, -1 when unset.
+	MaxBlankLines int
+	BlankLines    int
+	// TrimTrailingBlankLines forbids blank lines at the end of the file.
+	TrimTrailingBlankLines bool
+	// ForbidTabs reports any tab of the lines when indenting with spaces.
+	ForbidTabs bool
+	// HalfIndentPrefixes are the starts of the lines which may be indented by
+	// half the indent_size, e.g. the case labels, none unless allowed.
+	HalfIndentPrefixes [][]byte
+	// Suppressed contains the rules disabled by an inline directive.
+	Suppressed map[string]bool
+}
+
+// defaultHalfIndentPrefixes are the starts of the lines indented by half the
+// indent_size, the case labels of the C-like languages.
+const defaultHalfIndentPrefixes = "case, default"
+
+// blockCommentMarkers are the delimiters and the line prefix of a kind of block comments.
+type blockCommentMarkers struct {
+	Start  []byte
+	Prefix []byte
+	End    []byte
+}
+
+// splitMarkers splits the comma-separated list of comment markers.
+func splitMarkers(value string) []string {
+	markers := strings.Split(value, ",")
+	for i, marker := range markers {
+		markers[i] = strings.TrimSpace(marker)
+	}
+
+	return markers
+}
+
+// useBlockComment makes the markers the ones of the current block comment.
+func (def *definition) useBlockComment(markers blockCommentMarkers) {
+	def.BlockCommentStart = markers.Start
+	def.BlockComment = markers.Prefix
+	def.BlockCommentEnd = markers.End
 }
 
 func newDefinition(d *editorconfig.Definition) (*definition, error) { //nolint:cyclop,gocognit
 	def := &definition{
-		Definition: *d,
-		TabWidth:   d.TabWidth,
+		Definition:    *d,
+		TabWidth:      d.TabWidth,
+		MaxBlankLines: -1,
 	}
 
 	if def.Charset == "utf-8-bom" {
 		def.Charset = "utf-8 bom"
 	}
 
-	if d.IndentSize != "" && d.IndentSize != UnsetValue {
+	if tw, ok := def.Raw["tab_width"]; ok && tw != "" && tw != UnsetValue {
+		t, er := strconv.Atoi(tw)
+		if er != nil || t <= 0 {
+			return nil, fmt.Errorf(
+				"%w: .editorconfig: tab_width expected a positive number, got %q",
+				ErrConfiguration,
+				tw,
+			)
+		}
+	}
+
+	switch d.IndentSize {
+	case "", UnsetValue:
+	case TabValue:
+		// indent_size = tab uses the tab_width.
+		def.IndentSize = def.TabWidth
+		if def.IndentSize <= 0 {
+			def.IndentSize = DefaultTabWidth
+		}
+	default:
 		is, err := strconv.Atoi(d.IndentSize)
 		if err != nil {
-			return nil, fmt.Errorf("cannot convert indentsize %q to int: %w", d.IndentSize, err)
+			return nil, fmt.Errorf(
+				"%w: .editorconfig: indent_size expected a number, got %q",
+				ErrConfiguration,
+				d.IndentSize,
+			)
 		}
 
 		def.IndentSize = is
+
+		// tab_width defaults to indent_size.
+		if def.TabWidth <= 0 {
+			def.TabWidth = is
+		}
 	}
 
 	if def.IndentStyle != "" && def.IndentStyle != UnsetValue { //nolint:nestif
 		bs, ok := def.Raw["block_comment_start"]
 		if ok && bs != "" && bs != UnsetValue {
-			def.BlockCommentStart = []byte(bs)
-			bc, ok := def.Raw["block_comment"]
+			starts := splitMarkers(bs)
 
-			if ok && bc != "" && bs != UnsetValue {
-				def.BlockComment = []byte(bc)
+			var prefixes []string
+
+			bc, ok := def.Raw["block_comment"]
+			if ok && bc != "" && bc != UnsetValue {
+				prefixes = splitMarkers(bc)
 			}
 
 			be, ok := def.Raw["block_comment_end"]
@@ -63,8 +149,54 @@ func newDefinition(d *editorconfig.Definition) (*definition, error) { //nolint:c
 				)
 			}
 
-			def.BlockCommentEnd = []byte(be)
+			ends := splitMarkers(be)
+			if len(ends) != len(starts) || len(prefixes) > len(starts) {
+				return nil, fmt.Errorf(
+					"%w: .editorconfig: %d block_comment_end and at most as many block_comment were expected, got %d and %d",
+					ErrConfiguration,
+					len(starts),
+					len(ends),
+					len(prefixes),
+				)
+			}
+
+			for i, start := range starts {
+				markers := blockCommentMarkers{
+					Start: []byte(start),
+					End:   []byte(ends[i]),
+				}
+
+				if i < len(prefixes) && prefixes[i] != "" {
+					markers.Prefix = []byte(prefixes[i])
+				}
+
+				def.BlockComments = append(def.BlockComments, markers)
+			}
+
+			def.useBlockComment(def.BlockComments[0])
+		}
+
+		if lc, ok := def.Raw["line_comment"]; ok && lc != "" && lc != UnsetValue {
+			for _, marker := range splitMarkers(lc) {
+				if marker != "" {
+					def.LineComments = append(def.LineComments, []byte(marker))
+				}
+			}
+		}
+	}
+
+	if bs, ok := def.Raw["block_string_start"]; ok && bs != "" && bs != UnsetValue {
+		def.BlockStringStart = []byte(bs)
+
+		be, ok := def.Raw["block_string_end"]
+		if !ok || be == "" || be == UnsetValue {
+			return nil, fmt.Errorf(
+				"%w: .editorconfig: block_string_end was expected, none were found",
+				ErrConfiguration,
+			)
 		}
+
+		def.BlockStringEnd = []byte(be)
 	}
 
 	if mll, ok := def.Raw["max_line_length"]; ok && mll != "off" && mll != UnsetValue {
@@ -84,9 +216,171 @@ func newDefinition(d *editorconfig.Definition) (*definition, error) { //nolint:c
 		}
 	}
 
+	if mbl, ok := def.Raw["max_consecutive_blank_lines"]; ok && mbl != "" && mbl != "off" && mbl != UnsetValue {
+		mb, er := strconv.Atoi(mbl)
+		if er != nil || mb < 0 {
+			return nil, fmt.Errorf(
+				"%w: .editorconfig: max_consecutive_blank_lines expected a non-negative number, got %q",
+				ErrConfiguration,
+				mbl,
+			)
+		}
+
+		def.MaxBlankLines = mb
+	}
+
+	switch ttbl := def.Raw["trim_trailing_blank_lines"]; ttbl {
+	case "true":
+		def.TrimTrailingBlankLines = true
+	case "", "false", "off", UnsetValue:
+	default:
+		return nil, fmt.Errorf(
+			"%w: .editorconfig: trim_trailing_blank_lines expected a boolean, got %q",
+			ErrConfiguration,
+			ttbl,
+		)
+	}
+
+	switch ft := def.Raw["forbid_tabs"]; ft {
+	case "true":
+		def.ForbidTabs = true
+	case "", "false", UnsetValue:
+	default:
+		return nil, fmt.Errorf(
+			"%w: .editorconfig: forbid_tabs expected a boolean, got %q",
+			ErrConfiguration,
+			ft,
+		)
+	}
+
+	switch half := def.Raw["indent_size_half_allowed"]; half {
+	case "true":
+		if def.IndentSize <= 0 || def.IndentSize%2 != 0 {
+			return nil, fmt.Errorf(
+				"%w: .editorconfig: indent_size_half_allowed expected an even indent_size, got %q",
+				ErrConfiguration,
+				d.IndentSize,
+			)
+		}
+
+		prefixes := defaultHalfIndentPrefixes
+		if hp, ok := def.Raw["indent_size_half_prefix"]; ok && hp != "" && hp != UnsetValue {
+			prefixes = hp
+		}
+
+		for _, prefix := range splitMarkers(prefixes) {
+			if prefix != "" {
+				def.HalfIndentPrefixes = append(def.HalfIndentPrefixes, []byte(prefix))
+			}
+		}
+	case "", "false", UnsetValue:
+	default:
+		return nil, fmt.Errorf(
+			"%w: .editorconfig: indent_size_half_allowed expected a boolean, got %q",
+			ErrConfiguration,
+			half,
+		)
+	}
+
 	return def, nil
 }
 
+// Definition is the public view of the settings enforced on a file, the
+// values derived from the EditorConfig properties included.
+type Definition struct {
+	editorconfig.Definition
+	// IndentSize is the number of columns of an indentation level, tab_width for indent_size = tab.
+	IndentSize int
+	// TabWidth is the number of columns of a tab, 0 when nothing uses it.
+	TabWidth int
+	// MaxLength is the maximum line length, 0 when off.
+	MaxLength         int
+	BlockCommentStart []byte
+	BlockComment      []byte
+	BlockCommentEnd   []byte
+	BlockStringStart  []byte
+	BlockStringEnd    []byte
+	LineComments      [][]byte
+	// MaxBlankLines is the maximum of consecutive blank lines, -1 when unset.
+	MaxBlankLines          int
+	TrimTrailingBlankLines bool
+	ForbidTabs             bool
+	HalfIndentPrefixes     [][]byte
+}
+
+// ResolveDefinition looks up the .editorconfig files of the given file, which
+// doesn't have to exist, and computes the settings eclint would enforce.
+func ResolveDefinition(filename string) (*Definition, error) {
+	d, err := editorconfig.GetDefinitionForFilename(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get definition for %s: %w", filename, err)
+	}
+
+	return NewDefinition(d)
+}
+
+// NewDefinition computes the settings eclint would enforce using the
+// resolved EditorConfig definition.
+func NewDefinition(d *editorconfig.Definition) (*Definition, error) {
+	def, err := newDefinition(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Definition{
+		Definition:             def.Definition,
+		IndentSize:             def.IndentSize,
+		TabWidth:               def.TabWidth,
+		MaxLength:              def.MaxLength,
+		BlockCommentStart:      def.BlockCommentStart,
+		BlockComment:           def.BlockComment,
+		BlockCommentEnd:        def.BlockCommentEnd,
+		BlockStringStart:       def.BlockStringStart,
+		BlockStringEnd:         def.BlockStringEnd,
+		LineComments:           def.LineComments,
+		HalfIndentPrefixes:     def.HalfIndentPrefixes,
+		MaxBlankLines:          def.MaxBlankLines,
+		TrimTrailingBlankLines: def.TrimTrailingBlankLines,
+		ForbidTabs:             def.ForbidTabs,
+	}, nil
+}
+
+// Print outputs the properties in the key = value form, sorted by name, the
+// derived ones replacing their raw value, e.g. indent_size = tab.
+func (d *Definition) Print(w io.Writer) error {
+	properties := make(map[string]string, len(d.Raw)+3)
+	for name, value := range d.Raw {
+		properties[name] = value
+	}
+
+	if d.IndentSize > 0 {
+		properties["indent_size"] = strconv.Itoa(d.IndentSize)
+	}
+
+	if d.TabWidth > 0 {
+		properties["tab_width"] = strconv.Itoa(d.TabWidth)
+	}
+
+	if d.MaxLength > 0 {
+		properties["max_line_length"] = strconv.Itoa(d.MaxLength)
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s = %s\n", name, properties[name]); err != nil {
+			return fmt.Errorf("cannot write the properties: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // EOL returns the byte value of the given definition.
 func (def *definition) EOL() ([]byte, error) {
 	switch def.EndOfLine {
@@ -108,32 +402,411 @@ func (def *definition) EOL() ([]byte, error) {
 func OverrideDefinitionUsingPrefix(def *editorconfig.Definition, prefix string) error {
 	for k, v := range def.Raw {
 		if strings.HasPrefix(k, prefix) {
-			nk := k[len(prefix):]
-			def.Raw[nk] = v
-
-			switch nk {
-			case "indent_style":
-				def.IndentStyle = v
-			case "indent_size":
-				def.IndentSize = v
-			case "charset":
-				def.Charset = v
-			case "end_of_line":
-				def.EndOfLine = v
-			case "tab_width":
-				i, err := strconv.Atoi(v)
-				if err != nil {
-					return fmt.Errorf("tab_width cannot be set. %w", err)
-				}
-
-				def.TabWidth = i
-			case "trim_trailing_whitespace":
-				return fmt.Errorf("%v cannot be overridden: %w", nk, ErrNotImplemented)
-			case "insert_final_newline":
-				return fmt.Errorf("%v cannot be overridden: %w", nk, ErrNotImplemented)
+			if err := setProperty(def, k[len(prefix):], v); err != nil {
+				return err
 			}
 		}
 	}
 
 	return nil
 }
+
+// OverrideDefinition sets the properties, e.g. indent_size = 2, replacing
+// the ones of the definition.
+func OverrideDefinition(def *editorconfig.Definition, properties map[string]string) error {
+	if def.Raw == nil {
+		def.Raw = make(map[string]string)
+	}
+
+	for k, v := range properties {
+		if err := setProperty(def, strings.ToLower(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// propertiesKey is the context key of the properties overriding the definitions.
+type propertiesKey struct{}
+
+// WithProperties returns a context where the properties override the ones
+// of every definition, see OverrideDefinition.
+func WithProperties(ctx context.Context, properties map[string]string) context.Context {
+	return context.WithValue(ctx, propertiesKey{}, properties)
+}
+
+// propertiesFromContext returns the properties overriding the definitions.
+func propertiesFromContext(ctx context.Context) map[string]string {
+	properties, _ := ctx.Value(propertiesKey{}).(map[string]string)
+
+	return properties
+}
+
+// newDefinitionContext is newDefinition after overriding the properties of
+// the context, the given definition being left untouched.
+func newDefinitionContext(ctx context.Context, d *editorconfig.Definition) (*definition, error) {
+	if properties := propertiesFromContext(ctx); len(properties) > 0 {
+		o := *d
+		o.Raw = make(map[string]string, len(d.Raw)+len(properties))
+
+		for k, v := range d.Raw {
+			o.Raw[k] = v
+		}
+
+		if err := OverrideDefinition(&o, properties); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrConfiguration, err.Error())
+		}
+
+		d = &o
+	}
+
+	if prefix, ok := strictConfigFromContext(ctx); ok {
+		if err := checkUnknownProperties(d, prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	return newDefinition(d)
+}
+
+// knownProperties are the EditorConfig properties and the domain-specific
+// ones used by eclint, the others being ignored unless the configuration is strict.
+var knownProperties = map[string]bool{ //nolint:gochecknoglobals
+	"charset":                     true,
+	"end_of_line":                 true,
+	"indent_size":                 true,
+	"indent_style":                true,
+	"insert_final_newline":        true,
+	"spelling_language":           true,
+	"tab_width":                   true,
+	"trim_trailing_whitespace":    true,
+	"block_comment":               true,
+	"block_comment_end":           true,
+	"block_comment_start":         true,
+	"block_string_end":            true,
+	"block_string_start":          true,
+	"forbid_tabs":                 true,
+	"indent_size_half_allowed":    true,
+	"indent_size_half_prefix":     true,
+	"line_comment":                true,
+	"max_consecutive_blank_lines": true,
+	"max_line_length":             true,
+	"trim_trailing_blank_lines":   true,
+}
+
+// strictConfigKey is the context key of the prefix of the strict configuration.
+type strictConfigKey struct{}
+
+// WithStrictConfig returns a context where the unknown properties, e.g. a
+// typo, are configuration errors instead of being ignored. The known ones
+// may be prefixed, see OverrideDefinitionUsingPrefix, an empty prefix
+// allowing none.
+func WithStrictConfig(ctx context.Context, prefix string) context.Context {
+	return context.WithValue(ctx, strictConfigKey{}, prefix)
+}
+
+// strictConfigFromContext returns the prefix of the strict configuration, if strict.
+func strictConfigFromContext(ctx context.Context) (string, bool) {
+	prefix, ok := ctx.Value(strictConfigKey{}).(string)
+
+	return prefix, ok
+}
+
+// checkUnknownProperties reports the properties that aren't known, sorted by name.
+func checkUnknownProperties(def *editorconfig.Definition, prefix string) error {
+	unknown := make([]string, 0)
+
+	for name := range def.Raw {
+		if knownProperties[name] || (prefix != "" && knownProperties[strings.TrimPrefix(name, prefix)]) {
+			continue
+		}
+
+		unknown = append(unknown, strconv.Quote(name))
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	return fmt.Errorf("%w: .editorconfig: unknown properties %s", ErrConfiguration, strings.Join(unknown, ", "))
+}
+
+// DefaultProperties returns the properties of a sensible default for the
+// files without any EditorConfig files: lf, a final newline, and no trailing
+// whitespaces.
+func DefaultProperties() map[string]string {
+	return map[string]string{
+		"end_of_line":              "lf",
+		"insert_final_newline":     "true",
+		"trim_trailing_whitespace": "true",
+	}
+}
+
+// MergeDefaults sets the properties of the defaults which are missing from the definition.
+//
+// The properties of the definition are kept as is, even when unset.
+func MergeDefaults(def *editorconfig.Definition, defaults *editorconfig.Definition) error {
+	if def.Raw == nil {
+		def.Raw = make(map[string]string)
+	}
+
+	for k, v := range defaults.Raw {
+		if _, ok := def.Raw[k]; ok {
+			continue
+		}
+
+		if err := setProperty(def, k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setProperty sets the raw value of the property as well as its native field.
+func setProperty(def *editorconfig.Definition, name string, v string) error {
+	def.Raw[name] = v
+
+	switch name {
+	case "indent_style":
+		def.IndentStyle = v
+	case "indent_size":
+		def.IndentSize = v
+	case "charset":
+		def.Charset = v
+	case "end_of_line":
+		def.EndOfLine = v
+	case "tab_width":
+		if v == UnsetValue {
+			def.TabWidth = 0
+
+			return nil
+		}
+
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("tab_width cannot be set. %w", err)
+		}
+
+		def.TabWidth = i
+	case "trim_trailing_whitespace":
+		b, err := parseBoolOrUnset(v)
+		if err != nil {
+			return fmt.Errorf("%v cannot be set. %w", name, err)
+		}
+
+		def.TrimTrailingWhitespace = b
+	case "insert_final_newline":
+		b, err := parseBoolOrUnset(v)
+		if err != nil {
+			return fmt.Errorf("%v cannot be set. %w", name, err)
+		}
+
+		def.InsertFinalNewline = b
+	}
+
+	return nil
+}
+
+// hasRedundantTabWidth tells whether the tab_width is set while nothing makes use of it.
+func (def *definition) hasRedundantTabWidth() bool {
+	tw, ok := def.Raw["tab_width"]
+
+	return ok && tw != "" && tw != UnsetValue &&
+		def.IndentStyle == SpaceValue &&
+		def.Definition.IndentSize != TabValue &&
+		def.MaxLength == 0
+}
+
+// ConfigFile resolves the definitions using a single EditorConfig file.
+//
+// Unlike the usual lookup, the parent directories aren't searched and the
+// sections are matched against the path of each file relative to the
+// current working directory.
+type ConfigFile struct {
+	ec   *editorconfig.Editorconfig
+	dir  string
+	path string
+}
+
+// LoadConfigFile parses the given EditorConfig file.
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	ec, err := editorconfig.ParseFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot load %s: %v", ErrConfiguration, path, err) //nolint:errorlint
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the current directory: %w", err)
+	}
+
+	return &ConfigFile{
+		ec:   ec,
+		dir:  dir,
+		path: path,
+	}, nil
+}
+
+// Load returns the definition of the given file.
+func (c *ConfigFile) Load(filename string) (*editorconfig.Definition, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get absolute path of %s: %w", filename, err)
+	}
+
+	name := abs
+
+	if rel, err := filepath.Rel(c.dir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+
+	def, err := c.ec.GetDefinitionForFilename(filepath.ToSlash(name))
+	if err != nil {
+		return nil, fmt.Errorf("cannot get definition for %s: %w", filename, err)
+	}
+
+	return def, nil
+}
+
+// Sources returns the EditorConfig file used for every definition.
+func (c *ConfigFile) Sources() []string {
+	return []string{c.path}
+}
+
+// EnvConfigFile is the environment variable naming an EditorConfig file
+// whose properties are the defaults of every file, e.g. a global one kept in
+// the home directory.
+const EnvConfigFile = "EDITORCONFIG"
+
+// LoadEnvConfigFile parses the EditorConfig file named by the EDITORCONFIG
+// environment variable, it returns nil when the variable isn't set.
+func LoadEnvConfigFile() (*ConfigFile, error) {
+	path := os.Getenv(EnvConfigFile)
+	if path == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	return LoadConfigFile(path)
+}
+
+// MergeInto sets the properties matching the file which are missing from the
+// definition, the ones coming from the project always win.
+func (c *ConfigFile) MergeInto(def *editorconfig.Definition, filename string) error {
+	defaults, err := c.Load(filename)
+	if err != nil {
+		return err
+	}
+
+	return MergeDefaults(def, defaults)
+}
+
+// SourceParser is an EditorConfig parser remembering the files it read.
+type SourceParser struct {
+	editorconfig.Parser
+	sources []string
+}
+
+// NewSourceParser wraps the given parser.
+func NewSourceParser(parser editorconfig.Parser) *SourceParser {
+	return &SourceParser{
+		Parser: parser,
+	}
+}
+
+// ParseIni parses the given EditorConfig file, remembering it when it exists.
+func (p *SourceParser) ParseIni(filename string) (*editorconfig.Editorconfig, error) {
+	ec, err := p.Parser.ParseIni(filename)
+	if err == nil {
+		p.sources = append(p.sources, filename)
+	}
+
+	return ec, err //nolint:wrapcheck
+}
+
+// ParseIniGraceful parses the given EditorConfig file, remembering it when it exists.
+func (p *SourceParser) ParseIniGraceful(filename string) (*editorconfig.Editorconfig, error, error) {
+	ec, warning, err := p.Parser.ParseIniGraceful(filename)
+	if err == nil {
+		p.sources = append(p.sources, filename)
+	}
+
+	return ec, warning, err //nolint:wrapcheck
+}
+
+// Sources returns, and forgets, the files read since the previous call, the closest first.
+func (p *SourceParser) Sources() []string {
+	sources := p.sources
+	p.sources = nil
+
+	return sources
+}
+
+// ErrOutsideRoot represents a file that isn't within the root directory.
+var ErrOutsideRoot = errors.New("file is outside of the root directory")
+
+// RootParser is an EditorConfig parser ignoring the files above the root directory.
+type RootParser struct {
+	editorconfig.Parser
+	root string
+}
+
+// NewRootParser wraps the given parser, stopping the search at the root directory.
+func NewRootParser(parser editorconfig.Parser, root string) (*RootParser, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get absolute path of %s: %w", root, err)
+	}
+
+	return &RootParser{
+		Parser: parser,
+		root:   abs,
+	}, nil
+}
+
+// Contains tells whether the file is within the root directory.
+func (p *RootParser) Contains(filename string) bool {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(p.root, abs)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ParseIni parses the given EditorConfig file, the ones above the root not existing.
+func (p *RootParser) ParseIni(filename string) (*editorconfig.Editorconfig, error) {
+	if !p.Contains(filename) {
+		return nil, fmt.Errorf("%s is above the root %s: %w", filename, p.root, os.ErrNotExist)
+	}
+
+	return p.Parser.ParseIni(filename) //nolint:wrapcheck
+}
+
+// ParseIniGraceful parses the given EditorConfig file, the ones above the root not existing.
+func (p *RootParser) ParseIniGraceful(filename string) (*editorconfig.Editorconfig, error, error) {
+	if !p.Contains(filename) {
+		return nil, nil, fmt.Errorf("%s is above the root %s: %w", filename, p.root, os.ErrNotExist)
+	}
+
+	return p.Parser.ParseIniGraceful(filename) //nolint:wrapcheck
+}
+
+// parseBoolOrUnset reads a boolean property, unset giving nil.
+func parseBoolOrUnset(value string) (*bool, error) {
+	if value == UnsetValue {
+		return nil, nil //nolint:nilnil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a boolean: %w", value, err)
+	}
+
+	return &b, nil
+}
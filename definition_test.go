@@ -1,6 +1,13 @@
 package eclint_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/editorconfig/editorconfig-core-go/v2"
@@ -42,3 +49,530 @@ func TestOverridingUsingPrefix(t *testing.T) {
 		t.Errorf("tab_width not changed, got %d", def.TabWidth)
 	}
 }
+
+func TestOverridingUsingPrefixBooleans(t *testing.T) {
+	disabled := false
+	def := &editorconfig.Definition{
+		TrimTrailingWhitespace: &disabled,
+		InsertFinalNewline:     &disabled,
+		Raw: map[string]string{
+			"trim_trailing_whitespace":        "false",
+			"insert_final_newline":            "false",
+			"eclint_trim_trailing_whitespace": "true",
+			"eclint_insert_final_newline":     "true",
+		},
+	}
+
+	if err := eclint.OverrideDefinitionUsingPrefix(def, "eclint_"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The validators use the native fields.
+	file := []byte("hello \nworld")
+
+	errs := eclint.LintReaderWithDefinition(context.TODO(), def, "a.txt", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 2 {
+		t.Fatalf("two errors were expected, got %v", errs)
+	}
+
+	for i, rule := range []string{eclint.RuleTrailingWhitespace, eclint.RuleFinalNewline} {
+		var ve eclint.ValidationError
+		if ok := errors.As(errs[i], &ve); !ok || ve.Rule != rule {
+			t.Errorf("a %s error was expected, got %s", rule, errs[i])
+		}
+	}
+}
+
+func TestOverridingUsingPrefixUnset(t *testing.T) {
+	enabled := true
+	def := &editorconfig.Definition{
+		TabWidth:               3,
+		TrimTrailingWhitespace: &enabled,
+		InsertFinalNewline:     &enabled,
+	}
+
+	raw := make(map[string]string)
+	raw["@_tab_width"] = "unset"
+	raw["@_trim_trailing_whitespace"] = "unset"
+	raw["@_insert_final_newline"] = "false"
+	def.Raw = raw
+
+	if err := eclint.OverrideDefinitionUsingPrefix(def, "@_"); err != nil {
+		t.Fatal(err)
+	}
+
+	if def.TabWidth != 0 {
+		t.Errorf("tab_width not unset, got %d", def.TabWidth)
+	}
+
+	if def.TrimTrailingWhitespace != nil {
+		t.Errorf("trim_trailing_whitespace not unset, got %v", *def.TrimTrailingWhitespace)
+	}
+
+	if def.InsertFinalNewline == nil || *def.InsertFinalNewline {
+		t.Errorf("insert_final_newline not changed, got %v", def.InsertFinalNewline)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "generated.editorconfig")
+	content := []byte(`[*]
+indent_style = space
+
+[*.go]
+indent_style = tab
+
+[testdata/**.txt]
+indent_size = 3
+`)
+
+	if err := os.WriteFile(config, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := eclint.LoadConfigFile(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Filename    string
+		IndentStyle string
+		IndentSize  string
+	}{
+		{
+			Filename:    "lint.go",
+			IndentStyle: "tab",
+		}, {
+			Filename:    "cmd/eclint/main.go",
+			IndentStyle: "tab",
+		}, {
+			Filename:    "testdata/simple/simple.txt",
+			IndentStyle: "space",
+			IndentSize:  "3",
+		}, {
+			Filename:    "README.md",
+			IndentStyle: "space",
+		},
+	}
+
+	for _, tc := range tests {
+		def, err := cf.Load(tc.Filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if def.IndentStyle != tc.IndentStyle || def.IndentSize != tc.IndentSize {
+			t.Errorf(
+				"%s: %q %q was expected, got %q %q",
+				tc.Filename, tc.IndentStyle, tc.IndentSize, def.IndentStyle, def.IndentSize,
+			)
+		}
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	_, err := eclint.LoadConfigFile("testdata/missing/.editorconfig")
+	if !errors.Is(err, eclint.ErrConfiguration) {
+		t.Errorf("a configuration error was expected, got %v", err)
+	}
+}
+
+func TestSourceParser(t *testing.T) {
+	parser := eclint.NewSourceParser(editorconfig.NewCachedParser())
+	config := &editorconfig.Config{Parser: parser}
+
+	dir, err := filepath.Abs(filepath.Join("testdata", "unset"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := config.Load(filepath.Join(dir, "sub", "a.txt")); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "sub", ".editorconfig"),
+		filepath.Join(dir, ".editorconfig"),
+	}
+
+	sources := parser.Sources()
+	if len(sources) != len(expected) {
+		t.Fatalf("sources %v were expected, got %v", expected, sources)
+	}
+
+	for i, source := range expected {
+		if sources[i] != source {
+			t.Errorf("sources %v were expected, got %v", expected, sources)
+		}
+	}
+
+	if sources := parser.Sources(); len(sources) != 0 {
+		t.Errorf("the sources were expected to be forgotten, got %v", sources)
+	}
+}
+
+func TestRootParser(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("testdata", "unset"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := eclint.NewRootParser(editorconfig.NewCachedParser(), filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := eclint.NewSourceParser(root)
+	config := &editorconfig.Config{Parser: parser}
+
+	if _, err := config.Load(filepath.Join(dir, "sub", "a.txt")); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	expected := filepath.Join(dir, "sub", ".editorconfig")
+	if sources := parser.Sources(); len(sources) != 1 || sources[0] != expected {
+		t.Errorf("only %s was expected, got %v", expected, sources)
+	}
+
+	if !root.Contains(filepath.Join(dir, "sub", "a.txt")) {
+		t.Error("the file within the root was expected to be contained")
+	}
+
+	if root.Contains(filepath.Join(dir, "a.txt")) {
+		t.Error("the file above the root was expected not to be contained")
+	}
+
+	if root.Contains(filepath.Join(dir, "subway", "a.txt")) {
+		t.Error("the file of a sibling directory was expected not to be contained")
+	}
+}
+
+func TestLoadEnvConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	global := filepath.Join(dir, "global.editorconfig")
+	if err := os.WriteFile(global, []byte("[*]\ninsert_final_newline = true\nindent_style = tab\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "no_final_newline.txt")
+	if err := os.WriteFile(filename, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(eclint.EnvConfigFile, global)
+
+	defaults, err := eclint.LoadEnvConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := editorconfig.GetDefinitionForFilename(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := defaults.MergeInto(def, filename); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := eclint.LintWithDefinition(context.TODO(), def, filename)
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %v", errs)
+	}
+
+	var ve eclint.ValidationError
+	if ok := errors.As(errs[0], &ve); !ok || ve.Rule != eclint.RuleFinalNewline {
+		t.Errorf("a final newline error was expected, got %s", errs[0])
+	}
+}
+
+func TestLoadEnvConfigFileUnset(t *testing.T) {
+	t.Setenv(eclint.EnvConfigFile, "")
+
+	defaults, err := eclint.LoadEnvConfigFile()
+	if err != nil || defaults != nil {
+		t.Errorf("no defaults were expected, got %v, %v", defaults, err)
+	}
+}
+
+func TestMergeDefaults(t *testing.T) {
+	def := &editorconfig.Definition{
+		IndentStyle: eclint.SpaceValue,
+		Raw: map[string]string{
+			"indent_style":         eclint.SpaceValue,
+			"insert_final_newline": eclint.UnsetValue,
+		},
+	}
+
+	defaults := &editorconfig.Definition{
+		Raw: map[string]string{
+			"indent_style":         eclint.TabValue,
+			"insert_final_newline": "true",
+			"end_of_line":          "crlf",
+		},
+	}
+
+	if err := eclint.MergeDefaults(def, defaults); err != nil {
+		t.Fatal(err)
+	}
+
+	if def.IndentStyle != eclint.SpaceValue || def.Raw["indent_style"] != eclint.SpaceValue {
+		t.Errorf("the project indent_style should have been kept, got %q", def.IndentStyle)
+	}
+
+	if def.InsertFinalNewline != nil || def.Raw["insert_final_newline"] != eclint.UnsetValue {
+		t.Errorf("the project insert_final_newline should have been kept, got %v", def.InsertFinalNewline)
+	}
+
+	if def.EndOfLine != "crlf" {
+		t.Errorf("the default end_of_line was expected, got %q", def.EndOfLine)
+	}
+}
+
+func TestResolveDefinition(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Config     string
+		IndentSize int
+		TabWidth   int
+		MaxLength  int
+	}{
+		{
+			Name:       "indent_size = tab",
+			Config:     "indent_style = tab\nindent_size = tab\ntab_width = 4\n",
+			IndentSize: 4,
+			TabWidth:   4,
+		}, {
+			Name:       "indent_size = tab without tab_width",
+			Config:     "indent_style = tab\nindent_size = tab\n",
+			IndentSize: eclint.DefaultTabWidth,
+		}, {
+			Name:       "tab_width defaults to indent_size",
+			Config:     "indent_style = space\nindent_size = 2\nmax_line_length = 80\n",
+			IndentSize: 2,
+			TabWidth:   2,
+			MaxLength:  80,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			config := "root = true\n\n[*.c]\n" + tc.Config
+
+			if err := os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte(config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			def, err := eclint.ResolveDefinition(filepath.Join(dir, "main.c"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if def.IndentSize != tc.IndentSize || def.TabWidth != tc.TabWidth || def.MaxLength != tc.MaxLength {
+				t.Errorf(
+					"indent size %d, tab width %d, and max length %d were expected, got %d, %d, and %d",
+					tc.IndentSize, tc.TabWidth, tc.MaxLength,
+					def.IndentSize, def.TabWidth, def.MaxLength,
+				)
+			}
+		})
+	}
+}
+
+func TestDefinitionPrint(t *testing.T) {
+	dir := t.TempDir()
+	config := "root = true\n\n[*.go]\nindent_style = tab\nindent_size = tab\nmax_line_length = 120\n\n" +
+		"[*.md]\ntrim_trailing_whitespace = false\n"
+
+	if err := os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := eclint.ResolveDefinition(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := def.Print(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "indent_size = 8\nindent_style = tab\nmax_line_length = 120\ntab_width = 8\n"
+	if buf.String() != expected {
+		t.Errorf("%q was expected, got %q", expected, buf.String())
+	}
+}
+
+func TestWithProperties(t *testing.T) {
+	file := []byte("a line longer than ten characters\n")
+
+	def := &editorconfig.Definition{
+		Raw: map[string]string{"max_line_length": "10"},
+	}
+
+	errs := eclint.LintReaderWithDefinition(context.TODO(), def, "a.txt", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %v", errs)
+	}
+
+	ctx := eclint.WithProperties(context.TODO(), map[string]string{"max_line_length": "off"})
+
+	errs = eclint.LintReaderWithDefinition(ctx, def, "a.txt", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 0 {
+		t.Errorf("no errors were expected, got %v", errs)
+	}
+
+	if def.Raw["max_line_length"] != "10" {
+		t.Errorf("the definition was expected to be left untouched, got %q", def.Raw["max_line_length"])
+	}
+}
+
+func TestWithPropertiesInvalid(t *testing.T) {
+	file := []byte("hello\n")
+	def := &editorconfig.Definition{}
+
+	for _, properties := range []map[string]string{
+		{"max_line_length": "long"},
+		{"insert_final_newline": "maybe"},
+	} {
+		ctx := eclint.WithProperties(context.TODO(), properties)
+
+		errs := eclint.LintReaderWithDefinition(ctx, def, "a.txt", bytes.NewReader(file), int64(len(file)))
+		if len(errs) != 1 || !errors.Is(errs[0], eclint.ErrConfiguration) {
+			t.Errorf("%v: a configuration error was expected, got %v", properties, errs)
+		}
+	}
+}
+
+func TestOverrideDefinition(t *testing.T) {
+	def := &editorconfig.Definition{}
+
+	if err := eclint.OverrideDefinition(def, map[string]string{"Indent_Size": "2", "indent_style": "space"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if def.IndentSize != "2" || def.IndentStyle != "space" || def.Raw["indent_size"] != "2" {
+		t.Errorf("the properties were expected to be set, got %+v", def)
+	}
+}
+
+func TestStrictConfig(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Strict bool
+		Raw    map[string]string
+		Errors int
+	}{
+		{
+			Name:   "lenient",
+			Raw:    map[string]string{"bogus_property": "true", "indent_style": "space"},
+			Errors: 0,
+		}, {
+			Name:   "strict",
+			Strict: true,
+			Raw:    map[string]string{"bogus_property": "true", "indent_style": "space"},
+			Errors: 1,
+		}, {
+			Name:   "strict with known properties",
+			Strict: true,
+			Raw:    map[string]string{"spelling_language": "en-US", "max_line_length": "80", "eclint_indent_style": "space"},
+			Errors: 0,
+		}, {
+			Name:   "strict with an unknown prefixed property",
+			Strict: true,
+			Raw:    map[string]string{"eclint_bogus_property": "true"},
+			Errors: 1,
+		},
+	}
+
+	file := []byte("hello\n")
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.TODO()
+			if tc.Strict {
+				ctx = eclint.WithStrictConfig(ctx, "eclint_")
+			}
+
+			def := &editorconfig.Definition{Raw: tc.Raw}
+
+			errs := eclint.LintReaderWithDefinition(ctx, def, "a.txt", bytes.NewReader(file), int64(len(file)))
+			if len(errs) != tc.Errors {
+				t.Fatalf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+
+			for _, err := range errs {
+				if !errors.Is(err, eclint.ErrConfiguration) {
+					t.Errorf("a configuration error was expected, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestStrictConfigOverriddenProperties(t *testing.T) {
+	ctx := eclint.WithStrictConfig(context.TODO(), "")
+	ctx = eclint.WithProperties(ctx, map[string]string{"indnet_size": "2"})
+
+	file := []byte("hello\n")
+
+	errs := eclint.LintReaderWithDefinition(ctx, &editorconfig.Definition{}, "a.txt", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `"indnet_size"`) {
+		t.Errorf("the unknown property was expected to be reported, got %v", errs)
+	}
+}
+
+func TestDefaultProperties(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.txt")
+
+	def, err := editorconfig.GetDefinitionForFilename(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := []byte("hello \nworld\r\nbye")
+
+	// Without any .editorconfig, nothing is checked.
+	errs := eclint.LintReaderWithDefinition(context.TODO(), def, filename, bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 0 {
+		t.Fatalf("no errors were expected, got %v", errs)
+	}
+
+	if err := eclint.OverrideDefinition(def, eclint.DefaultProperties()); err != nil {
+		t.Fatal(err)
+	}
+
+	errs = eclint.LintReaderWithDefinition(context.TODO(), def, filename, bytes.NewReader(file), int64(len(file)))
+
+	rules := make([]string, 0, len(errs))
+
+	for _, err := range errs {
+		var ve eclint.ValidationError
+		if ok := errors.As(err, &ve); !ok {
+			t.Fatalf("a validation error was expected, got %v", err)
+		}
+
+		rules = append(rules, ve.Rule)
+	}
+
+	sort.Strings(rules)
+
+	expected := []string{eclint.RuleEndOfLine, eclint.RuleFinalNewline, eclint.RuleTrailingWhitespace}
+	sort.Strings(expected)
+
+	if strings.Join(rules, ",") != strings.Join(expected, ",") {
+		t.Errorf("%v were expected, got %v", expected, errs)
+	}
+}
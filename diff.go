@@ -0,0 +1,131 @@
+package eclint
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDiff represents an unified diff that cannot be parsed.
+var ErrInvalidDiff = errors.New("invalid unified diff")
+
+// hunkHeader matches the line numbers of a hunk, e.g. "@@ -1,3 +1,4 @@".
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`) //nolint:gochecknoglobals
+
+// ChangedLines contains the added lines, starting at 1, of each file.
+type ChangedLines map[string]map[int]struct{}
+
+// ParseDiff reads an unified diff, e.g. from `git diff`, and gathers the added lines.
+func ParseDiff(r io.Reader) (ChangedLines, error) { //nolint:cyclop
+	changes := make(ChangedLines)
+
+	var (
+		lines   map[int]struct{}
+		line    int
+		oldLeft int
+		newLeft int
+	)
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		text := sc.Text()
+
+		// Within a hunk
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if lines != nil {
+					lines[line] = struct{}{}
+				}
+
+				line++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				oldLeft--
+			case strings.HasPrefix(text, `\`):
+				// "\ No newline at end of file"
+			default:
+				line++
+				oldLeft--
+				newLeft--
+			}
+
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			filename := strings.TrimPrefix(text, "+++ ")
+			// diff -u adds a timestamp after a tab
+			filename, _, _ = strings.Cut(filename, "\t")
+
+			lines = nil
+
+			if filename != "/dev/null" {
+				filename = diffFilename(strings.TrimPrefix(filename, "b/"))
+				if _, ok := changes[filename]; !ok {
+					changes[filename] = make(map[int]struct{})
+				}
+
+				lines = changes[filename]
+			}
+		case strings.HasPrefix(text, "@@ "):
+			m := hunkHeader.FindStringSubmatch(text)
+			if m == nil {
+				return nil, fmt.Errorf("%w: bad hunk header %q", ErrInvalidDiff, text)
+			}
+
+			oldLeft = atoiOr(m[1], 1)
+			line = atoiOr(m[2], 0)
+			newLeft = atoiOr(m[3], 1)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read the diff: %w", err)
+	}
+
+	return changes, nil
+}
+
+// Filter keeps the errors found on the added lines of the file, and the
+// ones not related to any line, e.g. the charset.
+func (c ChangedLines) Filter(filename string, errs []error) []error {
+	lines := c[diffFilename(filename)]
+	kept := make([]error, 0, len(errs))
+
+	for _, err := range errs {
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok && ve.Line != nil {
+			if _, ok := lines[ve.Index+1]; !ok {
+				continue
+			}
+		}
+
+		kept = append(kept, err)
+	}
+
+	return kept
+}
+
+// diffFilename normalizes the filename so it matches the one from the diff.
+func diffFilename(filename string) string {
+	return filepath.ToSlash(filepath.Clean(filename))
+}
+
+// atoiOr converts the optional number of a hunk header.
+func atoiOr(s string, value int) int {
+	if s == "" {
+		return value
+	}
+
+	// The regular expression ensures it's a number.
+	i, _ := strconv.Atoi(s)
+
+	return i
+}
@@ -0,0 +1,87 @@
+package eclint_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"gitlab.com/greut/eclint"
+)
+
+const testDiff = `diff --git a/a.txt b/a.txt
+index 0123456..789abcd 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,4 @@
+ one
+-two
++deux
++++three
+ four
+@@ -10 +11,2 @@ context
++eleven
+ twelve
+\ No newline at end of file
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+diff --git a/sub/new.txt b/sub/new.txt
+new file mode 100644
+--- /dev/null
++++ b/sub/new.txt
+@@ -0,0 +1 @@
++new
+`
+
+func TestParseDiff(t *testing.T) {
+	changes, err := eclint.ParseDiff(strings.NewReader(testDiff))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := eclint.ChangedLines{
+		"a.txt":       {2: {}, 3: {}, 11: {}},
+		"sub/new.txt": {1: {}},
+	}
+
+	if !cmp.Equal(expected, changes) {
+		t.Errorf("diff %s", cmp.Diff(expected, changes))
+	}
+}
+
+func TestParseDiffFailure(t *testing.T) {
+	_, err := eclint.ParseDiff(strings.NewReader("+++ b/a.txt\n@@ -1 +a @@\n"))
+	if !errors.Is(err, eclint.ErrInvalidDiff) {
+		t.Errorf("an invalid diff error was expected, got %v", err)
+	}
+}
+
+func TestChangedLinesFilter(t *testing.T) {
+	changes := eclint.ChangedLines{
+		"sub/a.txt": {2: {}},
+	}
+
+	other := errors.New("random error")
+	charset := eclint.ValidationError{Rule: eclint.RuleCharset}
+	added := eclint.ValidationError{Line: []byte("two\n"), Index: 1}
+	kept := eclint.ValidationError{Line: []byte("one\n"), Index: 0}
+
+	errs := changes.Filter("./sub/a.txt", []error{other, charset, added, kept})
+	if len(errs) != 3 {
+		t.Fatalf("the errors of the added lines were expected, got %v", errs)
+	}
+
+	var ve eclint.ValidationError
+	if ok := errors.As(errs[2], &ve); !ok || ve.Index != added.Index {
+		t.Errorf("the error of the added line was expected, got %v", errs[2])
+	}
+
+	if errs := changes.Filter("b.txt", []error{added}); len(errs) != 0 {
+		t.Errorf("no errors were expected on an unchanged file, got %v", errs)
+	}
+}
@@ -1,2 +1,15 @@
 // Package eclint is a set of linters to for the EditorConfig rules
+//
+// The linting functions return a list of errors, the ones found within the
+// files are of the ValidationError type which holds the rule, the line, and
+// the position of the error.
+//
+//	for _, err := range eclint.Lint(ctx, "main.go") {
+//		var ve eclint.ValidationError
+//		if errors.As(err, &ve) {
+//			fmt.Println(ve.Rule, ve.Index+1, ve.Position+1, ve.Message)
+//		}
+//	}
+//
+// The logger, a logr.Logger, is taken from the context.
 package eclint
@@ -0,0 +1,57 @@
+// Package eclinttest enforces the EditorConfig rules from the Go tests, e.g.
+// on the files produced by a code generator.
+//
+//	func TestGenerated(t *testing.T) {
+//		eclinttest.AssertClean(t, "zz_generated.go")
+//	}
+//
+// It is kept out of the eclint package so the latter doesn't import testing.
+package eclinttest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gitlab.com/greut/eclint"
+)
+
+// Errors lints the file like eclint.Lint, without the nil errors.
+func Errors(filename string) []error {
+	errs := make([]error, 0)
+
+	for _, err := range eclint.Lint(context.Background(), filename) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// AssertClean marks the test as failed, with the formatted errors, when the
+// file breaks any of the rules of its .editorconfig files.
+func AssertClean(t testing.TB, filename string) {
+	t.Helper()
+
+	errs := Errors(filename)
+	if len(errs) == 0 {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+
+	opt := &eclint.Option{
+		NoColors:      true,
+		ShowAllErrors: true,
+		Stdout:        buf,
+	}
+
+	if err := eclint.PrintErrors(context.Background(), opt, filename, errs); err != nil {
+		t.Errorf("%s has %d errors, which cannot be printed: %v", filename, len(errs), err)
+
+		return
+	}
+
+	t.Errorf("%s has %d errors:\n%s", filename, len(errs), buf)
+}
@@ -0,0 +1,79 @@
+package eclinttest_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/greut/eclint/eclinttest"
+)
+
+// recorder catches the failures of the assertions.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// writeFiles creates the files within a directory having its own .editorconfig.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files[".editorconfig"] = "root = true\n\n[*]\ntrim_trailing_whitespace = true\ninsert_final_newline = true\n"
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestAssertClean(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"clean.txt": "hello\nworld\n",
+	})
+
+	r := &recorder{TB: t}
+
+	eclinttest.AssertClean(r, filepath.Join(dir, "clean.txt"))
+
+	if len(r.failures) != 0 {
+		t.Errorf("no failures were expected, got %v", r.failures)
+	}
+}
+
+func TestAssertCleanFailure(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"dirty.txt": "hello \nworld",
+	})
+
+	filename := filepath.Join(dir, "dirty.txt")
+
+	if errs := eclinttest.Errors(filename); len(errs) != 2 {
+		t.Fatalf("two errors were expected, got %v", errs)
+	}
+
+	r := &recorder{TB: t}
+
+	eclinttest.AssertClean(r, filename)
+
+	if len(r.failures) != 1 {
+		t.Fatalf("one failure was expected, got %v", r.failures)
+	}
+
+	for _, expected := range []string{"2 errors", "trailing whitespaces", "^"} {
+		if !strings.Contains(r.failures[0], expected) {
+			t.Errorf("%q was expected in the failure, got %q", expected, r.failures[0])
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package eclint
+
+import "context"
+
+// eolConsistencyKey is the context key enabling the end of line consistency check.
+type eolConsistencyKey struct{}
+
+// WithEndOfLineConsistency returns a context where the lines of the files
+// without an end_of_line have to end like their first line.
+func WithEndOfLineConsistency(ctx context.Context) context.Context {
+	return context.WithValue(ctx, eolConsistencyKey{}, true)
+}
+
+// eolConsistencyFromContext tells whether the end of lines have to be consistent.
+func eolConsistencyFromContext(ctx context.Context) bool {
+	consistent, _ := ctx.Value(eolConsistencyKey{}).(bool)
+
+	return consistent
+}
+
+// endOfLineName returns the end_of_line value of the line ending, if any.
+func endOfLineName(ending []byte) string {
+	switch string(ending) {
+	case "\r\n":
+		return "crlf"
+	case "\r":
+		return "cr"
+	case "\n":
+		return "lf"
+	default:
+		return ""
+	}
+}
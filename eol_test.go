@@ -0,0 +1,96 @@
+package eclint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+)
+
+func TestEndOfLineConsistency(t *testing.T) {
+	tests := []struct {
+		Name      string
+		EndOfLine string
+		File      []byte
+		Index     int
+		Position  int
+	}{
+		{
+			Name:     "crlf among lf",
+			File:     []byte("a\nb\nc\r\nd\n"),
+			Index:    2,
+			Position: 1,
+		}, {
+			Name:     "lf among crlf",
+			File:     []byte("a\r\nb\r\nc\nd\r\n"),
+			Index:    2,
+			Position: 1,
+		}, {
+			Name:  "consistent",
+			File:  []byte("a\r\nb\r\nc\r\nd"),
+			Index: -1,
+		}, {
+			Name:      "end_of_line set",
+			EndOfLine: "crlf",
+			File:      []byte("a\r\nb\r\nc\r\n"),
+			Index:     -1,
+		},
+	}
+
+	ctx := WithEndOfLineConsistency(context.TODO())
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				EndOfLine: tc.EndOfLine,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := validate(ctx, bytes.NewReader(tc.File), int64(len(tc.File)), "utf-8", def)
+			if tc.Index < 0 {
+				if len(errs) != 0 {
+					t.Fatalf("no errors were expected, got %v", errs)
+				}
+
+				return
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("one error was expected, got %v", errs)
+			}
+
+			var ve ValidationError
+			if ok := errors.As(errs[0], &ve); !ok {
+				t.Fatalf("a validation error was expected, got %s", errs[0])
+			}
+
+			if ve.Rule != RuleEndOfLine || ve.Index != tc.Index || ve.Position != tc.Position {
+				t.Errorf(
+					"%s error expected at %d:%d, got %s at %d:%d",
+					RuleEndOfLine, tc.Index, tc.Position, ve.Rule, ve.Index, ve.Position,
+				)
+			}
+		})
+	}
+}
+
+func TestEndOfLineConsistencyDisabled(t *testing.T) {
+	def, err := newDefinition(&editorconfig.Definition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := []byte("a\nb\nc\r\nd\n")
+
+	if errs := validate(context.TODO(), bytes.NewReader(file), int64(len(file)), "utf-8", def); len(errs) != 0 {
+		t.Errorf("no errors were expected, got %v", errs)
+	}
+}
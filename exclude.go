@@ -0,0 +1,41 @@
+package eclint
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+)
+
+// MatchExclude returns the first pattern matching the filename, or an empty string.
+//
+// The patterns use forward slashes, so does the filename once normalized,
+// e.g. `vendor/**` matches `vendor\lib.go` on Windows.
+func MatchExclude(patterns []string, filename string) (string, error) {
+	return matchExclude(patterns, toSlash(filename, filepath.Separator))
+}
+
+func matchExclude(patterns []string, filename string) (string, error) {
+	for _, pattern := range patterns {
+		ok, err := editorconfig.FnmatchCase(pattern, filename)
+		if err != nil {
+			return "", fmt.Errorf("cannot match %q: %w", pattern, err)
+		}
+
+		if ok {
+			return pattern, nil
+		}
+	}
+
+	return "", nil
+}
+
+// toSlash is filepath.ToSlash for the given separator.
+func toSlash(filename string, separator rune) string {
+	if separator == '/' {
+		return filename
+	}
+
+	return strings.ReplaceAll(filename, string(separator), "/")
+}
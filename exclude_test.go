@@ -0,0 +1,111 @@
+package eclint
+
+import (
+	"testing"
+)
+
+func TestMatchExcludeBackslash(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Filename  string
+		Separator rune
+		Pattern   string
+	}{
+		{
+			Name:      "windows path",
+			Filename:  `vendor\github.com\lib.go`,
+			Separator: '\\',
+			Pattern:   "vendor/**",
+		}, {
+			Name:      "unix path",
+			Filename:  "vendor/github.com/lib.go",
+			Separator: '/',
+			Pattern:   "vendor/**",
+		}, {
+			Name:      "backslash within a unix file name",
+			Filename:  `vendor\lib.go`,
+			Separator: '/',
+			Pattern:   "",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := matchExclude([]string{"*.md", "vendor/**"}, toSlash(tc.Filename, tc.Separator))
+			if err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			if pattern != tc.Pattern {
+				t.Errorf("pattern %q was expected, got %q", tc.Pattern, pattern)
+			}
+		})
+	}
+}
+
+func TestMatchExclude(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Patterns []string
+		Filename string
+		Pattern  string
+	}{
+		{
+			Name:     "no patterns",
+			Patterns: nil,
+			Filename: "main.go",
+			Pattern:  "",
+		}, {
+			Name:     "single pattern",
+			Patterns: []string{"vendor/**"},
+			Filename: "vendor/github.com/lib.go",
+			Pattern:  "vendor/**",
+		}, {
+			Name:     "second pattern",
+			Patterns: []string{"vendor/**", "node_modules/**", "*.min.js"},
+			Filename: "node_modules/lib/index.js",
+			Pattern:  "node_modules/**",
+		}, {
+			Name:     "first matching pattern",
+			Patterns: []string{"dist/*.min.js", "dist/**"},
+			Filename: "dist/app.min.js",
+			Pattern:  "dist/*.min.js",
+		}, {
+			Name:     "braces",
+			Patterns: []string{"*.{js,css}"},
+			Filename: "style.css",
+			Pattern:  "*.{js,css}",
+		}, {
+			Name:     "braces within any directory",
+			Patterns: []string{"*.{js,css}", "**.{js,css}"},
+			Filename: "static/style.css",
+			Pattern:  "**.{js,css}",
+		}, {
+			Name:     "no match",
+			Patterns: []string{"vendor/**", "*.{js,css}"},
+			Filename: "main.go",
+			Pattern:  "",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := MatchExclude(tc.Patterns, tc.Filename)
+			if err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			if pattern != tc.Pattern {
+				t.Errorf("pattern %q was expected, got %q", tc.Pattern, pattern)
+			}
+		})
+	}
+}
@@ -1,25 +1,62 @@
 package eclint
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/editorconfig/editorconfig-core-go/v2"
 	"github.com/go-logr/logr"
 	"github.com/karrick/godirwalk"
 )
 
+// DefaultGitTimeout is how long git is waited for, see WithGitTimeout.
+const DefaultGitTimeout = 10 * time.Second
+
+// ErrGitTimeout represents a git command that didn't answer in time.
+var ErrGitTimeout = errors.New("git timed out")
+
+// gitTimeoutKey is the context key of the git timeout.
+type gitTimeoutKey struct{}
+
+// WithGitTimeout returns a context where the git commands are stopped after
+// the duration, e.g. on a hanging network filesystem, none stopping them.
+func WithGitTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, gitTimeoutKey{}, timeout)
+}
+
+// gitContext returns the context of a git command, stopped after the timeout.
+func gitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout, ok := ctx.Value(gitTimeoutKey{}).(time.Duration)
+	if !ok {
+		timeout = DefaultGitTimeout
+	}
+
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
 // ListFilesContext lists the files in an asynchronous fashion
 //
 // When its empty, it relies on `git ls-files` first, which
 // would fail if `git` is not present or the current working
-// directory is not managed by it. In that case, it work the
-// current working directory.
+// directory is not managed by it. When git doesn't answer in time,
+// see WithGitTimeout, it walks the current working directory instead.
 //
 // When args are given, it recursively walks into them.
+//
+// Either way, the files ignored by the .eclintignore files are skipped.
 func ListFilesContext(ctx context.Context, args ...string) (<-chan string, <-chan error) {
 	if len(args) > 0 {
 		return WalkContext(ctx, args...)
@@ -31,13 +68,116 @@ func ListFilesContext(ctx context.Context, args ...string) (<-chan string, <-cha
 
 	log.V(3).Info("fallback to `git ls-files`", "dir", dir)
 
-	return GitLsFilesContext(ctx, dir)
+	matcher, err := newIgnoreMatcher(dir)
+	if err != nil {
+		filesChan := make(chan string)
+		errChan := make(chan error, 1)
+		errChan <- err
+
+		close(filesChan)
+		close(errChan)
+
+		return filesChan, errChan
+	}
+
+	filesChan := make(chan string, 128)
+	errsChan := make(chan error, 1)
+
+	go func() {
+		defer close(filesChan)
+		defer close(errsChan)
+
+		fileChan, errChan := GitLsFilesContext(ctx, dir)
+		fileChan, errChan = filterIgnoredContext(ctx, matcher, fileChan, errChan)
+
+		err := forwardFilesContext(ctx, filesChan, fileChan, errChan)
+		if errors.Is(err, ErrGitTimeout) {
+			log.V(1).Info("walking the directory instead of listing the files known to git", "dir", dir, "reason", err.Error())
+
+			// The ignore files are honored by the walk.
+			fileChan, errChan = WalkContext(ctx, dir)
+			err = forwardFilesContext(ctx, filesChan, fileChan, errChan)
+		}
+
+		if err != nil {
+			errsChan <- err
+		}
+	}()
+
+	return filesChan, errsChan
+}
+
+// forwardFilesContext sends the files to the channel and returns the error, if any.
+func forwardFilesContext(
+	ctx context.Context,
+	filesChan chan<- string,
+	fileChan <-chan string,
+	errChan <-chan error,
+) error {
+	for filename := range fileChan {
+		select {
+		case filesChan <- filename:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	if err, ok := <-errChan; ok {
+		return err
+	}
+
+	return nil
+}
+
+// WalkOptions changes how the paths are walked.
+//
+// The symbolic links to directories aren't followed by default, when they are
+// each directory is only visited once, which protects against the cycles.
+// The directories of the version control systems, e.g. .git, are skipped by
+// default. The files and directories ignored by the .eclintignore files
+// always are.
+type WalkOptions struct {
+	// GitIgnore skips the files and directories ignored by git.
+	GitIgnore bool
+	// FollowSymlinks walks into the symbolic links to directories.
+	FollowSymlinks bool
+	// IncludeVCS walks into the directories of the version control systems.
+	IncludeVCS bool
+}
+
+// vcsDirs are the names of the directories of the version control systems.
+var vcsDirs = map[string]struct{}{ //nolint:gochecknoglobals
+	".git": {},
+	".hg":  {},
+	".svn": {},
 }
 
 // WalkContext iterates on each path item recursively (asynchronously).
 //
-// Future work: use godirwalk.
+// When a directory is managed by git, the files and directories ignored
+// by it (e.g. .gitignore) are skipped.
 func WalkContext(ctx context.Context, paths ...string) (<-chan string, <-chan error) {
+	return WalkWithOptionsContext(ctx, WalkOptions{GitIgnore: true}, paths...)
+}
+
+// WalkAllContext is like WalkContext without skipping the files ignored by git.
+func WalkAllContext(ctx context.Context, paths ...string) (<-chan string, <-chan error) {
+	return WalkWithOptionsContext(ctx, WalkOptions{}, paths...)
+}
+
+// WalkWithOptionsContext iterates on each path item recursively (asynchronously)
+// as set by the options.
+//
+// A path containing glob characters, e.g. `**/*.md` or `src/*.js`, walks its
+// directory and keeps the matching files only, `**/` matching any directory
+// including none.
+func WalkWithOptionsContext( //nolint:gocognit,cyclop,funlen
+	ctx context.Context,
+	opts WalkOptions,
+	paths ...string,
+) (<-chan string, <-chan error) {
+	log := logr.FromContextOrDiscard(ctx)
+
 	filesChan := make(chan string, 128)
 	errChan := make(chan error, 1)
 
@@ -48,13 +188,128 @@ func WalkContext(ctx context.Context, paths ...string) (<-chan string, <-chan er
 		for _, path := range paths {
 			// shortcircuit files
 			if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
-				filesChan <- path
+				select {
+				case filesChan <- path:
+				case <-ctx.Done():
+					return
+				}
+
+				continue
+			}
+
+			pattern := ""
+			if isGlob(path) {
+				pattern = filepath.ToSlash(path)
+				path = globBase(pattern)
+			}
+
+			var ignored map[string]struct{}
+
+			// visited contains the real path of the directories, when following the symbolic links.
+			visited := make(map[string]struct{})
+
+			if opts.GitIgnore {
+				var err error
+
+				ignored, err = gitIgnoredContext(ctx, path)
+				if err != nil {
+					errChan <- err
+
+					break
+				}
+			}
+
+			matcher, err := newIgnoreMatcher(path)
+			if err != nil {
+				errChan <- err
 
 				break
 			}
 
-			err := godirwalk.Walk(path, &godirwalk.Options{
+			err = godirwalk.Walk(path, &godirwalk.Options{
 				Callback: func(filename string, de *godirwalk.Dirent) error {
+					if !opts.IncludeVCS && filename != path && de.IsDir() {
+						if _, ok := vcsDirs[de.Name()]; ok {
+							log.V(4).Info("skipped version control directory", "filename", filename)
+
+							return godirwalk.SkipThis
+						}
+					}
+
+					if filename != path {
+						abs, err := filepath.Abs(filename)
+						if err != nil {
+							return fmt.Errorf("cannot get absolute path of %s: %w", filename, err)
+						}
+
+						// The parent directories were skipped when ignored.
+						ok, err := matcher.match(abs, de.IsDir())
+						if err != nil {
+							return err
+						}
+
+						if ok {
+							log.V(4).Info("skipped by "+IgnoreFileName, "filename", filename)
+
+							if de.IsDir() {
+								return godirwalk.SkipThis
+							}
+
+							return nil
+						}
+					}
+
+					if len(ignored) > 0 && filename != path {
+						abs, err := filepath.Abs(filename)
+						if err != nil {
+							return fmt.Errorf("cannot get absolute path of %s: %w", filename, err)
+						}
+
+						if _, ok := ignored[abs]; ok {
+							if de.IsDir() {
+								return godirwalk.SkipThis
+							}
+
+							return nil
+						}
+					}
+
+					if pattern != "" {
+						if ok, err := de.IsDirOrSymlinkToDir(); err == nil && ok {
+							return nil
+						}
+
+						ok, err := matchGlob(pattern, filepath.ToSlash(filename))
+						if err != nil {
+							return fmt.Errorf("cannot match %q: %w", pattern, err)
+						}
+
+						if !ok {
+							return nil
+						}
+					}
+
+					if opts.FollowSymlinks {
+						if ok, err := de.IsDirOrSymlinkToDir(); err == nil && ok {
+							target, err := filepath.EvalSymlinks(filename)
+							if err != nil {
+								return fmt.Errorf("cannot resolve %s: %w", filename, err)
+							}
+
+							if _, ok := visited[target]; ok {
+								log.V(4).Info("symlink skipped, directory already visited", "filename", filename, "target", target)
+
+								return godirwalk.SkipThis
+							}
+
+							visited[target] = struct{}{}
+
+							if de.IsSymlink() {
+								log.V(4).Info("symlink followed", "filename", filename, "target", target)
+							}
+						}
+					}
+
 					select {
 					case filesChan <- filename:
 						return nil
@@ -62,7 +317,8 @@ func WalkContext(ctx context.Context, paths ...string) (<-chan string, <-chan er
 						return fmt.Errorf("walking dir got interrupted: %w", ctx.Err())
 					}
 				},
-				Unsorted: true,
+				FollowSymbolicLinks: opts.FollowSymlinks,
+				Unsorted:            true,
 			})
 			if err != nil {
 				errChan <- err
@@ -75,12 +331,140 @@ func WalkContext(ctx context.Context, paths ...string) (<-chan string, <-chan er
 	return filesChan, errChan
 }
 
+// isGlob tells whether the path contains any glob characters.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[{")
+}
+
+// globBase returns the directory of the pattern before its first glob character.
+func globBase(pattern string) string {
+	i := strings.IndexAny(pattern, "*?[{")
+	base := pattern[:strings.LastIndex(pattern[:i], "/")+1]
+
+	switch base {
+	case "":
+		return "."
+	case "/":
+		return filepath.FromSlash(base)
+	default:
+		return filepath.FromSlash(strings.TrimSuffix(base, "/"))
+	}
+}
+
+// matchGlob matches the filename against the pattern, each `**/` matching no directory as well.
+func matchGlob(pattern string, filename string) (bool, error) {
+	// The walk of . gives relative file names.
+	filename = strings.TrimPrefix(filename, "./")
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	ok, err := editorconfig.FnmatchCase(pattern, filename)
+	if err != nil || ok {
+		return ok, err //nolint:wrapcheck
+	}
+
+	for i := strings.Index(pattern, "**/"); i >= 0; {
+		if ok, err := matchGlob(pattern[:i]+pattern[i+3:], filename); err != nil || ok {
+			return ok, err
+		}
+
+		j := strings.Index(pattern[i+3:], "**/")
+		if j < 0 {
+			break
+		}
+
+		i += 3 + j
+	}
+
+	return false, nil
+}
+
+// ReadFilesContext reads the file names from the reader (asynchronously).
+//
+// There is one file name per line, empty lines and the ones starting with
+// a # are ignored. E.g. the output of `git diff --name-only`.
+func ReadFilesContext(ctx context.Context, r io.Reader) (<-chan string, <-chan error) {
+	filesChan := make(chan string, 128)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(filesChan)
+		defer close(errChan)
+
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			filename := strings.TrimSpace(sc.Text())
+			if filename == "" || strings.HasPrefix(filename, "#") {
+				continue
+			}
+
+			select {
+			case filesChan <- filename:
+				// everything is good
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := sc.Err(); err != nil {
+			errChan <- fmt.Errorf("cannot read the file names: %w", err)
+		}
+	}()
+
+	return filesChan, errChan
+}
+
+// gitIgnoredContext returns the absolute paths of the files and directories
+// ignored by git within the given directory.
+//
+// When git is missing, or the directory is not managed by it, nothing
+// is returned.
+func gitIgnoredContext(ctx context.Context, dir string) (map[string]struct{}, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get absolute path of %s: %w", dir, err)
+	}
+
+	gitCtx, cancel := gitContext(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(
+		gitCtx,
+		"git", "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory", "--", ".",
+	)
+	cmd.Dir = abs
+
+	output, err := cmd.Output()
+	if err != nil {
+		log.V(3).Info("cannot list the files ignored by git", "dir", dir, "error", err.Error())
+
+		return nil, nil
+	}
+
+	ignored := make(map[string]struct{})
+
+	for _, f := range bytes.Split(output, []byte{0}) {
+		if len(f) == 0 {
+			continue
+		}
+
+		ignored[filepath.Join(abs, string(f))] = struct{}{}
+	}
+
+	return ignored, nil
+}
+
 // GitLsFilesContext returns the list of file base on what is in the git index (asynchronously).
 //
 // -z is mandatory as some repositories non-ASCII file names which creates
-// quoted and escaped file names. This method also returns directories for
-// any submodule there is. Submodule will be skipped afterwards and thus
-// not checked.
+// quoted and escaped file names. The files of the submodules are listed
+// too, each one resolving its own .editorconfig files. This method also
+// returns directories for any submodule not checked out, which will be
+// skipped afterwards.
+//
+// When git doesn't answer in time, see WithGitTimeout, the error is an
+// ErrGitTimeout.
 func GitLsFilesContext(ctx context.Context, path string) (<-chan string, <-chan error) {
 	filesChan := make(chan string, 128)
 	errChan := make(chan error, 1)
@@ -89,10 +473,15 @@ func GitLsFilesContext(ctx context.Context, path string) (<-chan string, <-chan
 		defer close(filesChan)
 		defer close(errChan)
 
-		output, err := exec.CommandContext(ctx, "git", "ls-files", "-z", path).Output()
+		gitCtx, cancel := gitContext(ctx)
+		defer cancel()
+
+		output, err := exec.CommandContext(gitCtx, "git", "ls-files", "-z", "--recurse-submodules", path).Output()
 		if err != nil {
 			var e *exec.ExitError
-			if ok := errors.As(err, &e); ok {
+			if ctx.Err() == nil && errors.Is(gitCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("%w: git ls-files did not answer in time, %v", ErrGitTimeout, err) //nolint:errorlint
+			} else if ok := errors.As(err, &e); ok {
 				if e.ExitCode() == 128 {
 					err = fmt.Errorf("not a git repository: %w", e)
 				} else {
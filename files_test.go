@@ -2,9 +2,18 @@ package eclint_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 
 	"gitlab.com/greut/eclint"
 )
@@ -165,6 +174,393 @@ outer:
 	}
 }
 
+func TestWalkFiles(t *testing.T) {
+	files := []string{
+		filepath.Join(testdataSimple, "simple.txt"),
+		filepath.Join(testdataSimple, ".editorconfig"),
+	}
+
+	fs := make([]string, 0, len(files))
+	fsChan, errChan := eclint.WalkContext(context.TODO(), files...)
+
+outer:
+	for {
+		select {
+		case err, ok := <-errChan:
+			if ok && err != nil {
+				t.Fatal(err)
+			}
+		case f, ok := <-fsChan:
+			if !ok {
+				break outer
+			}
+			fs = append(fs, f)
+		}
+	}
+
+	if diff := cmp.Diff(files, fs); diff != "" {
+		t.Errorf("every file was expected, got a difference %s", diff)
+	}
+}
+
+func TestWalkGitIgnore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("skipping test requiring git to be installed")
+	}
+
+	d := t.TempDir()
+
+	if err := exec.Command("git", "init", "-q", d).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		".gitignore":      "*.log\nbuild/\n",
+		"a.txt":           "a\n",
+		"b.log":           "b\n",
+		"build/c.txt":     "c\n",
+		"sub/d.txt":       "d\n",
+		"sub/build/e.txt": "e\n",
+	}
+
+	for name, content := range files {
+		filename := filepath.Join(d, name)
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		Name     string
+		Walk     func(context.Context, ...string) (<-chan string, <-chan error)
+		Expected []string
+	}{
+		{
+			Name:     "gitignore",
+			Walk:     eclint.WalkContext,
+			Expected: []string{".", ".gitignore", "a.txt", "sub", "sub/d.txt"},
+		}, {
+			Name: "no gitignore",
+			Walk: eclint.WalkAllContext,
+			Expected: []string{
+				".", ".gitignore", "a.txt", "b.log", "build", "build/c.txt",
+				"sub", "sub/build", "sub/build/e.txt", "sub/d.txt",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := []string{}
+			fsChan, errChan := tc.Walk(context.TODO(), d)
+
+		outer:
+			for {
+				select {
+				case err, ok := <-errChan:
+					if ok && err != nil {
+						t.Fatal(err)
+					}
+				case f, ok := <-fsChan:
+					if !ok {
+						break outer
+					}
+
+					rel, err := filepath.Rel(d, f)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if rel != ".git" && !isInGitDir(rel) {
+						fs = append(fs, filepath.ToSlash(rel))
+					}
+				}
+			}
+
+			sort.Strings(fs)
+
+			if !cmp.Equal(tc.Expected, fs) {
+				t.Errorf("diff %s", cmp.Diff(tc.Expected, fs))
+			}
+		})
+	}
+}
+
+func TestWalkSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	outside := filepath.Join(dir, "outside")
+
+	for _, d := range []string{filepath.Join(root, "sub"), outside} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, f := range []string{filepath.Join(root, "sub", "a.txt"), filepath.Join(outside, "b.txt")} {
+		if err := os.WriteFile(f, []byte("hello\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a cycle to the root, and a link to a directory out of it.
+	if err := os.Symlink(root, filepath.Join(root, "sub", "cycle")); err != nil {
+		t.Skipf("skipping test requiring symbolic links: %s", err)
+	}
+
+	if err := os.Symlink(outside, filepath.Join(root, "outside")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Name           string
+		FollowSymlinks bool
+		Files          []string
+	}{
+		{
+			Name:           "not following",
+			FollowSymlinks: false,
+			Files:          []string{"a.txt"},
+		}, {
+			Name:           "following",
+			FollowSymlinks: true,
+			Files:          []string{"a.txt", "b.txt"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := eclint.WalkOptions{FollowSymlinks: tc.FollowSymlinks}
+			fsChan, errChan := eclint.WalkWithOptionsContext(context.TODO(), opts, root)
+
+			fs := []string{}
+
+		outer:
+			for {
+				select {
+				case err, ok := <-errChan:
+					if ok && err != nil {
+						t.Fatal(err)
+					}
+				case f, ok := <-fsChan:
+					if !ok {
+						break outer
+					}
+
+					if strings.HasSuffix(f, ".txt") {
+						fs = append(fs, filepath.Base(f))
+					}
+				}
+			}
+
+			sort.Strings(fs)
+
+			if diff := cmp.Diff(tc.Files, fs); diff != "" {
+				t.Errorf("each file was expected once, got a difference %s", diff)
+			}
+		})
+	}
+}
+
+func TestWalkVCS(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, f := range []string{"a.txt", ".git/config", ".git/refs/heads/main", "sub/.git/HEAD", ".gitignore"} {
+		filename := filepath.Join(dir, filepath.FromSlash(f))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte("hello\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		Name       string
+		IncludeVCS bool
+		Files      []string
+	}{
+		{
+			Name:  "skipped",
+			Files: []string{".gitignore", "a.txt"},
+		}, {
+			Name:       "included",
+			IncludeVCS: true,
+			Files:      []string{".git/config", ".git/refs/heads/main", ".gitignore", "a.txt", "sub/.git/HEAD"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := eclint.WalkOptions{IncludeVCS: tc.IncludeVCS}
+			fsChan, errChan := eclint.WalkWithOptionsContext(context.TODO(), opts, dir)
+
+			fs := []string{}
+
+		outer:
+			for {
+				select {
+				case err, ok := <-errChan:
+					if ok && err != nil {
+						t.Fatal(err)
+					}
+				case f, ok := <-fsChan:
+					if !ok {
+						break outer
+					}
+
+					if fi, err := os.Stat(f); err == nil && !fi.IsDir() {
+						rel, err := filepath.Rel(dir, f)
+						if err != nil {
+							t.Fatal(err)
+						}
+
+						fs = append(fs, filepath.ToSlash(rel))
+					}
+				}
+			}
+
+			sort.Strings(fs)
+
+			if diff := cmp.Diff(tc.Files, fs); diff != "" {
+				t.Errorf("the files were expected, got a difference %s", diff)
+			}
+		})
+	}
+}
+
+func TestWalkGlob(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, f := range []string{"a.md", "docs/b.md", "docs/c.txt", "src/a.js", "src/sub/b.js", "src/c.go"} {
+		filename := filepath.Join(dir, filepath.FromSlash(f))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte("hello\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		Name    string
+		Pattern string
+		Files   []string
+	}{
+		{
+			Name:    "markdown files",
+			Pattern: "**/*.md",
+			Files:   []string{"a.md", "docs/b.md"},
+		}, {
+			Name:    "javascript files of src",
+			Pattern: "src/*.js",
+			Files:   []string{"src/a.js"},
+		}, {
+			Name:    "javascript files within src",
+			Pattern: "src/**/*.js",
+			Files:   []string{"src/a.js", "src/sub/b.js"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			pattern := filepath.ToSlash(dir) + "/" + tc.Pattern
+			fsChan, errChan := eclint.WalkAllContext(context.TODO(), pattern)
+
+			fs := []string{}
+
+		outer:
+			for {
+				select {
+				case err, ok := <-errChan:
+					if ok && err != nil {
+						t.Fatal(err)
+					}
+				case f, ok := <-fsChan:
+					if !ok {
+						break outer
+					}
+
+					rel, err := filepath.Rel(dir, f)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					fs = append(fs, filepath.ToSlash(rel))
+				}
+			}
+
+			sort.Strings(fs)
+
+			if diff := cmp.Diff(tc.Files, fs); diff != "" {
+				t.Errorf("the matching files were expected, got a difference %s", diff)
+			}
+		})
+	}
+}
+
+func isInGitDir(rel string) bool {
+	for dir := filepath.Dir(rel); dir != "."; dir = filepath.Dir(dir) {
+		if dir == ".git" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestReadFiles(t *testing.T) {
+	r := strings.NewReader("a.txt\n\n# comment\r\nsub/b.txt\r\n  \nc d.txt")
+
+	fs := make([]string, 0)
+	fsChan, errChan := eclint.ReadFilesContext(context.TODO(), r)
+
+outer:
+	for {
+		select {
+		case err, ok := <-errChan:
+			if ok && err != nil {
+				t.Fatal(err)
+			}
+		case f, ok := <-fsChan:
+			if !ok {
+				break outer
+			}
+			fs = append(fs, f)
+		}
+	}
+
+	expected := []string{"a.txt", "sub/b.txt", "c d.txt"}
+	if !cmp.Equal(expected, fs) {
+		t.Errorf("diff %s", cmp.Diff(expected, fs))
+	}
+}
+
 func TestGitLsFiles(t *testing.T) {
 	skipNoGit(t)
 
@@ -193,6 +589,87 @@ outer:
 	}
 }
 
+func TestGitLsFilesSubmodules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("skipping test requiring git to be installed")
+	}
+
+	d := t.TempDir()
+	sub := filepath.Join(d, "sub")
+	top := filepath.Join(d, "top")
+
+	git := func(dir string, args ...string) {
+		t.Helper()
+
+		args = append([]string{
+			"-c", "user.name=eclint",
+			"-c", "user.email=eclint@example.org",
+			"-c", "protocol.file.allow=always",
+		}, args...)
+
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+
+	for _, dir := range []string{sub, top} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		git(dir, "init", "-q")
+	}
+
+	if err := os.WriteFile(filepath.Join(sub, "a.txt"), []byte("a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	git(sub, "add", "a.txt")
+	git(sub, "commit", "-q", "-m", "a")
+
+	if err := os.WriteFile(filepath.Join(top, "b.txt"), []byte("b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	git(top, "add", "b.txt")
+	git(top, "submodule", "add", "-q", sub, "sub")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if err := os.Chdir(cwd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err := os.Chdir(top); err != nil {
+		t.Fatal(err)
+	}
+
+	fileChan, errChan := eclint.GitLsFilesContext(context.TODO(), ".")
+
+	files := make([]string, 0)
+	for filename := range fileChan {
+		files = append(files, filepath.ToSlash(filename))
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(files)
+
+	if diff := cmp.Diff([]string{".gitmodules", "b.txt", "sub/a.txt"}, files); diff != "" {
+		t.Errorf("the files of the submodule were expected (-want +got):\n%s", diff)
+	}
+}
+
 func TestGitLsFilesFailure(t *testing.T) {
 	skipNoGit(t)
 
@@ -217,3 +694,77 @@ func skipNoGit(t *testing.T) {
 		t.Skip("skipping test requiring .git to be present")
 	}
 }
+
+func TestListFilesGitTimeout(t *testing.T) {
+	hangingGit(t)
+
+	d := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(d, "a.txt"), []byte("a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if err := os.Chdir(cwd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err := os.Chdir(d); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := eclint.WithGitTimeout(context.TODO(), 50*time.Millisecond)
+
+	fileChan, errChan := eclint.ListFilesContext(ctx)
+
+	files := make([]string, 0)
+	for filename := range fileChan {
+		if fi, err := os.Stat(filename); err == nil && !fi.IsDir() {
+			files = append(files, filepath.Base(filename))
+		}
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"a.txt"}, files); diff != "" {
+		t.Errorf("the directory was expected to be walked (-want +got):\n%s", diff)
+	}
+}
+
+func TestGitLsFilesTimeout(t *testing.T) {
+	hangingGit(t)
+
+	ctx := eclint.WithGitTimeout(context.TODO(), 50*time.Millisecond)
+
+	_, errChan := eclint.GitLsFilesContext(ctx, ".")
+
+	if err := <-errChan; !errors.Is(err, eclint.ErrGitTimeout) {
+		t.Errorf("a timeout was expected, got %v", err)
+	}
+}
+
+// hangingGit puts a git that never answers first in the PATH.
+func hangingGit(t *testing.T) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test requiring a shell script")
+	}
+
+	bin := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\nexec sleep 10\n"), 0o700) //nolint:gosec
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
@@ -7,21 +7,90 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/editorconfig/editorconfig-core-go/v2"
 	"github.com/go-logr/logr"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
-// FixWithDefinition does the hard work of validating the given file.
-func FixWithDefinition(ctx context.Context, d *editorconfig.Definition, filename string) error {
-	def, err := newDefinition(d)
+// FixWithDefinition does the hard work of fixing the given file.
+//
+// The file is replaced atomically, unless some errors could not be fixed.
+// In that case, the file is left untouched and those errors are returned.
+func FixWithDefinition(ctx context.Context, d *editorconfig.Definition, filename string) []error {
+	original, fixed, errs := fixWithFilename(ctx, d, filename)
+	if len(errs) != 0 || fixed == nil || bytes.Equal(original, fixed) {
+		return errs
+	}
+
+	stat, err := os.Stat(filename)
 	if err != nil {
-		return err
+		return []error{fmt.Errorf("cannot stat %s. %w", filename, err)}
+	}
+
+	if err := writeFileAtomically(filename, fixed, stat.Mode()); err != nil {
+		return []error{fmt.Errorf("cannot fix %s: %w", filename, err)}
+	}
+
+	log := logr.FromContextOrDiscard(ctx)
+	log.V(1).Info("bytes written", "total", len(fixed))
+
+	return nil
+}
+
+// DiffWithDefinition writes the changes the fix would do as an unified diff.
+//
+// Like FixWithDefinition, it returns the errors that could not be fixed.
+func DiffWithDefinition(ctx context.Context, d *editorconfig.Definition, filename string, w io.Writer) []error {
+	original, fixed, errs := fixWithFilename(ctx, d, filename)
+	if len(errs) != 0 || fixed == nil || bytes.Equal(original, fixed) {
+		return errs
+	}
+
+	diff := difflib.UnifiedDiff{
+		A:        diffLines(original),
+		B:        diffLines(fixed),
+		FromFile: filename,
+		ToFile:   filename,
+		Context:  3,
+	}
+
+	if err := difflib.WriteUnifiedDiff(w, diff); err != nil {
+		return []error{fmt.Errorf("cannot write the diff of %s: %w", filename, err)}
+	}
+
+	return nil
+}
+
+// diffLines splits the content while marking a missing final newline like diff does.
+func diffLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+
+	last := len(lines) - 1
+	if lines[last] == "" {
+		return lines[:last]
+	}
+
+	lines[last] += "\n\\ No newline at end of file\n"
+
+	return lines
+}
+
+// fixWithFilename returns the original and the fixed content of the file.
+//
+// The fixed content is linted again, any error left means it cannot be
+// fixed automatically.
+func fixWithFilename(ctx context.Context, d *editorconfig.Definition, filename string) ([]byte, []byte, []error) {
+	def, err := newDefinitionContext(ctx, d)
+	if err != nil {
+		return nil, nil, []error{err}
 	}
 
 	stat, err := os.Stat(filename)
 	if err != nil {
-		return fmt.Errorf("cannot stat %s. %w", filename, err)
+		return nil, nil, []error{fmt.Errorf("cannot stat %s. %w", filename, err)}
 	}
 
 	log := logr.FromContextOrDiscard(ctx)
@@ -29,85 +98,114 @@ func FixWithDefinition(ctx context.Context, d *editorconfig.Definition, filename
 	if stat.IsDir() {
 		log.V(2).Info("skipped directory")
 
-		return nil
+		return nil, nil, nil
 	}
 
-	fileSize := stat.Size()
-	mode := stat.Mode()
-
-	r, err := fixWithFilename(ctx, def, filename, fileSize)
+	original, err := os.ReadFile(filename)
 	if err != nil {
-		return fmt.Errorf("cannot fix %s: %w", filename, err)
+		return nil, nil, []error{fmt.Errorf("cannot read %s. %w", filename, err)}
 	}
 
-	if r == nil {
-		return nil
+	if len(original) == 0 {
+		log.V(2).Info("skipped empty file")
+
+		return nil, nil, nil
 	}
 
-	// XXX keep mode as is.
-	fp, err := os.OpenFile(filename, os.O_WRONLY|os.O_TRUNC, mode) //nolint:nosnakecase
-	if err != nil {
-		return fmt.Errorf("cannot open %s using %s: %w", filename, mode, err)
+	content := original
+	if rulesFromContext(ctx).enabled(RuleCharset) {
+		content = fixByteOrderMark(original, def.Charset)
 	}
-	defer fp.Close()
 
-	n, err := io.Copy(fp, r)
+	r := bufio.NewReader(bytes.NewReader(content))
+
+	charset, isBinary, err := ProbeCharsetOrBinary(ctx, r, expectedCharset(ctx, def))
 	if err != nil {
-		return fmt.Errorf("error copying file: %w", err)
+		return nil, nil, []error{err}
 	}
 
-	log.V(1).Info("bytes written", "total", n)
+	if isBinary {
+		log.V(2).Info("binary file detected and skipped")
 
-	return nil
-}
+		return nil, nil, nil
+	}
+
+	log.V(2).Info("charset probed", "charset", charset)
 
-func fixWithFilename(ctx context.Context, def *definition, filename string, fileSize int64) (io.Reader, error) {
-	fp, err := os.Open(filename)
+	out, err := fix(ctx, r, int64(len(content)), charset, def)
 	if err != nil {
-		return nil, fmt.Errorf("cannot open %s. %w", filename, err)
+		return nil, nil, []error{fmt.Errorf("cannot fix %s: %w", filename, err)}
 	}
 
-	defer fp.Close()
-
-	r := bufio.NewReader(fp)
+	fixed, err := io.ReadAll(out)
+	if err != nil {
+		return nil, nil, []error{fmt.Errorf("cannot read the fixed content of %s: %w", filename, err)}
+	}
 
-	ok, err := probeReadable(fp, r)
+	// The definition keeps some state, e.g. when inside a block comment.
+	def, err = newDefinitionContext(ctx, d)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read %s. %w", filename, err)
+		return nil, nil, []error{err}
 	}
 
-	log := logr.FromContextOrDiscard(ctx)
+	errs := lintReader(ctx, def, filename, bufio.NewReader(bytes.NewReader(fixed)), int64(len(fixed)))
+	if len(errs) != 0 {
+		log.V(1).Info("some errors cannot be fixed, file left untouched", "count", len(errs))
 
-	if !ok {
-		log.V(2).Info("skipped unreadable or empty file")
+		return original, nil, errs
+	}
+
+	return original, fixed, nil
+}
 
-		return nil, nil
+// writeFileAtomically writes the data into a temporary file before moving it.
+//
+// A symbolic link is kept, its target being the file replaced.
+func writeFileAtomically(filename string, data []byte, mode os.FileMode) error {
+	filename, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return fmt.Errorf("cannot resolve the symbolic links: %w", err)
 	}
 
-	charset, isBinary, err := ProbeCharsetOrBinary(ctx, r, def.Charset)
+	fp, err := os.CreateTemp(filepath.Dir(filename), fmt.Sprintf(".%s.*.eclint", filepath.Base(filename)))
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("cannot create temporary file: %w", err)
 	}
 
-	if isBinary {
-		log.V(2).Info("binary file detected and skipped")
+	tmp := fp.Name()
+
+	defer os.Remove(tmp)
+
+	if _, err := fp.Write(data); err != nil {
+		fp.Close()
 
-		return nil, nil
+		return fmt.Errorf("error writing file: %w", err)
 	}
 
-	log.V(2).Info("charset probed", "charset", charset)
+	if err := fp.Close(); err != nil {
+		return fmt.Errorf("cannot close %s: %w", tmp, err)
+	}
+
+	if err := os.Chmod(tmp, mode); err != nil {
+		return fmt.Errorf("cannot set mode %s: %w", mode, err)
+	}
+
+	if err := os.Rename(tmp, filename); err != nil {
+		return fmt.Errorf("cannot rename %s: %w", tmp, err)
+	}
 
-	return fix(ctx, r, fileSize, charset, def)
+	return nil
 }
 
-func fix( //nolint:funlen
-	_ context.Context,
+func fix( //nolint:funlen,cyclop
+	ctx context.Context,
 	r io.Reader,
 	fileSize int64,
 	_ string,
 	def *definition,
 ) (io.Reader, error) {
 	buf := bytes.NewBuffer([]byte{})
+	rules := rulesFromContext(ctx)
 
 	size := def.IndentSize
 	if def.TabWidth != 0 {
@@ -140,31 +238,40 @@ func fix( //nolint:funlen
 		)
 	}
 
-	eol, err := def.EOL()
-	if err != nil {
-		return nil, fmt.Errorf("cannot get EOL: %w", err)
+	if !rules.enabled(RuleIndentStyle) {
+		size = 0
 	}
 
-	trimTrailingWhitespace := false
-	if def.TrimTrailingWhitespace != nil {
-		trimTrailingWhitespace = *def.TrimTrailingWhitespace
+	var eol []byte
+
+	if def.EndOfLine != "" && def.EndOfLine != UnsetValue && rules.enabled(RuleEndOfLine) {
+		e, err := def.EOL()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get EOL: %w", err)
+		}
+
+		eol = e
 	}
 
-	errs := ReadLines(r, fileSize, func(index int, data []byte, isEOF bool) error {
+	errs := ReadLinesContext(ctx, r, fileSize, func(index int, data []byte, isEOF bool) error {
 		if size != 0 {
 			data = fixTabAndSpacePrefix(data, c, x)
 		}
 
-		if trimTrailingWhitespace {
-			data = fixTrailingWhitespace(data)
+		if rules.enabled(RuleTrailingWhitespace) {
+			data = fixTrailingWhitespace(data, def)
 		}
 
-		if def.EndOfLine != "" && !isEOF {
+		if eol != nil && (!isEOF || hasEndOfLine(data)) {
 			data = bytes.TrimRight(data, "\r\n")
 
 			data = append(data, eol...)
 		}
 
+		if isEOF && def.InsertFinalNewline != nil && rules.enabled(RuleFinalNewline) {
+			data = fixInsertFinalNewline(data, eol, *def.InsertFinalNewline)
+		}
+
 		_, err := buf.Write(data)
 		if err != nil {
 			return fmt.Errorf("error writing into buffer: %w", err)
@@ -180,6 +287,45 @@ func fix( //nolint:funlen
 	return buf, nil
 }
 
+// fixByteOrderMark adds the BOM required by utf-8-bom, or removes the one forbidden by utf-8 and ascii.
+//
+// The other charsets, or a file starting with another BOM, are left untouched.
+func fixByteOrderMark(data []byte, charset string) []byte {
+	switch charset {
+	case "utf-8 bom":
+		if detectCharsetUsingBOM(data) != "" {
+			return data
+		}
+
+		return append(append(make([]byte, 0, len(utf8Bom)+len(data)), utf8Bom...), data...)
+	case Utf8, ASCII:
+		return bytes.TrimPrefix(data, utf8Bom)
+	default:
+		return data
+	}
+}
+
+// fixInsertFinalNewline adds or removes the final newline of the last line.
+//
+// Without any end of line defined, lf is used.
+func fixInsertFinalNewline(data []byte, eol []byte, insertFinalNewline bool) []byte {
+	hasEOL := bytes.HasSuffix(data, []byte{lf}) || bytes.HasSuffix(data, []byte{cr})
+
+	if !insertFinalNewline {
+		return bytes.TrimRight(data, "\r\n")
+	}
+
+	if hasEOL || len(data) == 0 {
+		return data
+	}
+
+	if eol == nil {
+		eol = []byte{lf}
+	}
+
+	return append(data, eol...)
+}
+
 // fixTabAndSpacePrefix replaces any `x` by `c` in the given `data`.
 func fixTabAndSpacePrefix(data []byte, c []byte, x []byte) []byte {
 	newData := make([]byte, 0, len(data))
@@ -208,8 +354,14 @@ func fixTabAndSpacePrefix(data []byte, c []byte, x []byte) []byte {
 	return data
 }
 
-// fixTrailingWhitespace replaces any whitespace or tab from the end of the line.
-func fixTrailingWhitespace(data []byte) []byte {
+// fixTrailingWhitespace removes any whitespace or tab from the end of the line.
+//
+// The line ending is kept as is, and nothing is done unless trim_trailing_whitespace is true.
+func fixTrailingWhitespace(data []byte, def *definition) []byte {
+	if def.TrimTrailingWhitespace == nil || !*def.TrimTrailingWhitespace {
+		return data
+	}
+
 	i := len(data) - 1
 
 	// u -> v is the range to clean
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/editorconfig/editorconfig-core-go/v2"
@@ -97,6 +100,69 @@ func TestFixEndOfLine(t *testing.T) {
 	}
 }
 
+func TestFixEndOfLineConversions(t *testing.T) {
+	tests := []struct {
+		Name      string
+		EndOfLine string
+		File      []byte
+		Expected  []byte
+	}{
+		{
+			Name:      "crlf to lf",
+			EndOfLine: "lf",
+			File:      []byte("A file\r\nwith many\r\nlines\r\n"),
+			Expected:  []byte("A file\nwith many\nlines\n"),
+		}, {
+			Name:      "lf to crlf",
+			EndOfLine: "crlf",
+			File:      []byte("A file\nwith many\nlines\n"),
+			Expected:  []byte("A file\r\nwith many\r\nlines\r\n"),
+		}, {
+			Name:      "cr to lf",
+			EndOfLine: "lf",
+			File:      []byte("A file\rwith many\rlines\r"),
+			Expected:  []byte("A file\nwith many\nlines\n"),
+		}, {
+			Name:      "mixed to lf",
+			EndOfLine: "lf",
+			File:      []byte("A file\r\nwith many\rlines\n"),
+			Expected:  []byte("A file\nwith many\nlines\n"),
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				EndOfLine: tc.EndOfLine,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+			out, err := fix(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if err != nil {
+				t.Fatalf("no errors where expected, got %s", err)
+			}
+
+			result, err := io.ReadAll(out)
+			if err != nil {
+				t.Fatalf("cannot read result %s", err)
+			}
+
+			if !cmp.Equal(tc.Expected, result) {
+				t.Errorf("diff %s", cmp.Diff(tc.Expected, result))
+			}
+		})
+	}
+}
+
 func TestFixIndentStyle(t *testing.T) {
 	tests := []struct {
 		Name        string
@@ -197,6 +263,10 @@ func TestFixTrimTrailingWhitespace(t *testing.T) {
 		},
 	}
 
+	trimTrailingWhitespace := true
+	def := &definition{}
+	def.TrimTrailingWhitespace = &trimTrailingWhitespace
+
 	for _, tc := range tests {
 		tc := tc
 
@@ -204,7 +274,7 @@ func TestFixTrimTrailingWhitespace(t *testing.T) {
 			t.Parallel()
 
 			for _, l := range tc.Lines {
-				m := fixTrailingWhitespace(l)
+				m := fixTrailingWhitespace(l, def)
 
 				err := checkTrimTrailingWhitespace(m)
 				if err != nil {
@@ -214,3 +284,305 @@ func TestFixTrimTrailingWhitespace(t *testing.T) {
 		})
 	}
 }
+
+func TestFixTrailingWhitespaceKeepsLineEnding(t *testing.T) {
+	tests := []struct {
+		Name                   string
+		TrimTrailingWhitespace bool
+		Line                   []byte
+		Expected               []byte
+	}{
+		{
+			Name:                   "only whitespaces and crlf",
+			TrimTrailingWhitespace: true,
+			Line:                   []byte(" \t \r\n"),
+			Expected:               []byte("\r\n"),
+		}, {
+			Name:                   "cr",
+			TrimTrailingWhitespace: true,
+			Line:                   []byte("a line \r"),
+			Expected:               []byte("a line\r"),
+		}, {
+			Name:                   "inner whitespaces",
+			TrimTrailingWhitespace: true,
+			Line:                   []byte("\ta \t line\t\n"),
+			Expected:               []byte("\ta \t line\n"),
+		}, {
+			Name:                   "disabled",
+			TrimTrailingWhitespace: false,
+			Line:                   []byte("a line \n"),
+			Expected:               []byte("a line \n"),
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def := &definition{}
+			def.TrimTrailingWhitespace = &tc.TrimTrailingWhitespace
+
+			result := fixTrailingWhitespace(tc.Line, def)
+			if !cmp.Equal(tc.Expected, result) {
+				t.Errorf("diff %s", cmp.Diff(tc.Expected, result))
+			}
+		})
+	}
+}
+
+func TestFixInsertFinalNewline(t *testing.T) {
+	tests := []struct {
+		Name               string
+		EndOfLine          string
+		InsertFinalNewline bool
+		File               []byte
+		Expected           []byte
+	}{
+		{
+			Name:               "missing final newline",
+			EndOfLine:          "lf",
+			InsertFinalNewline: true,
+			File:               []byte("A file\nwithout a final newline."),
+			Expected:           []byte("A file\nwithout a final newline.\n"),
+		}, {
+			Name:               "missing final newline using crlf",
+			EndOfLine:          "crlf",
+			InsertFinalNewline: true,
+			File:               []byte("A file\r\nwithout a final newline."),
+			Expected:           []byte("A file\r\nwithout a final newline.\r\n"),
+		}, {
+			Name:               "missing final newline without end_of_line",
+			InsertFinalNewline: true,
+			File:               []byte("A file\nwithout a final newline."),
+			Expected:           []byte("A file\nwithout a final newline.\n"),
+		}, {
+			Name:               "extraneous final newline",
+			EndOfLine:          "lf",
+			InsertFinalNewline: false,
+			File:               []byte("A file\nwith a final newline.\n"),
+			Expected:           []byte("A file\nwith a final newline."),
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				EndOfLine:          tc.EndOfLine,
+				InsertFinalNewline: &tc.InsertFinalNewline,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+			out, err := fix(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if err != nil {
+				t.Fatalf("no errors where expected, got %s", err)
+			}
+
+			result, err := io.ReadAll(out)
+			if err != nil {
+				t.Fatalf("cannot read result %s", err)
+			}
+
+			if !cmp.Equal(tc.Expected, result) {
+				t.Errorf("diff %s", cmp.Diff(tc.Expected, result))
+			}
+		})
+	}
+}
+
+func TestFixWithDefinition(t *testing.T) {
+	ctx := context.TODO()
+	insertFinalNewline := true
+	trimTrailingWhitespace := true
+	def := &editorconfig.Definition{
+		EndOfLine:              "lf",
+		IndentStyle:            "tab",
+		IndentSize:             "2",
+		InsertFinalNewline:     &insertFinalNewline,
+		TrimTrailingWhitespace: &trimTrailingWhitespace,
+	}
+
+	filename := filepath.Join(t.TempDir(), "file.txt")
+
+	if err := os.WriteFile(filename, []byte("A file \r\n  with spaces"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	diff := bytes.NewBuffer(nil)
+	if errs := DiffWithDefinition(ctx, def, filename, diff); len(errs) != 0 {
+		t.Fatalf("no errors were expected, got %s", errs[0])
+	}
+
+	if !strings.Contains(diff.String(), "+\twith spaces\n") {
+		t.Errorf("the diff was expected to contain the fixed line, got %q", diff.String())
+	}
+
+	if errs := FixWithDefinition(ctx, def, filename); len(errs) != 0 {
+		t.Fatalf("no errors were expected, got %s", errs[0])
+	}
+
+	result, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte("A file\n\twith spaces\n")
+	if !cmp.Equal(expected, result) {
+		t.Errorf("diff %s", cmp.Diff(expected, result))
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("no temporary files were expected, got %d entries", len(entries))
+	}
+}
+
+func TestFixWithDefinitionSymlink(t *testing.T) {
+	ctx := context.TODO()
+	trimTrailingWhitespace := true
+	def := &editorconfig.Definition{
+		TrimTrailingWhitespace: &trimTrailingWhitespace,
+	}
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link.txt")
+
+	if err := os.WriteFile(target, []byte("A file \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symbolic links are not supported", err)
+	}
+
+	if errs := FixWithDefinition(ctx, def, link); len(errs) != 0 {
+		t.Fatalf("no errors were expected, got %s", errs[0])
+	}
+
+	stat, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stat.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("the symbolic link was expected to be kept, got %s", stat.Mode())
+	}
+
+	result, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte("A file\n")
+	if !cmp.Equal(expected, result) {
+		t.Errorf("diff %s", cmp.Diff(expected, result))
+	}
+}
+
+func TestFixWithDefinitionUnfixable(t *testing.T) {
+	ctx := context.TODO()
+	def := &editorconfig.Definition{
+		EndOfLine:   "lf",
+		IndentStyle: "space",
+		IndentSize:  "4",
+	}
+
+	filename := filepath.Join(t.TempDir(), "file.txt")
+	file := []byte("A file\r\n  with a wrong indentation\r\n")
+
+	if err := os.WriteFile(filename, file, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := FixWithDefinition(ctx, def, filename)
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %d", len(errs))
+	}
+
+	result, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(file, result) {
+		t.Errorf("the file was expected to be untouched, diff %s", cmp.Diff(file, result))
+	}
+}
+
+func TestFixByteOrderMark(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Charset  string
+		File     []byte
+		Expected []byte
+	}{
+		{
+			Name:     "add the BOM",
+			Charset:  "utf-8-bom",
+			File:     []byte("A file\n"),
+			Expected: []byte("\xef\xbb\xbfA file\n"),
+		}, {
+			Name:     "strip the BOM",
+			Charset:  "utf-8",
+			File:     []byte("\xef\xbb\xbfA file\n"),
+			Expected: []byte("A file\n"),
+		}, {
+			Name:     "keep the BOM",
+			Charset:  "utf-8-bom",
+			File:     []byte("\xef\xbb\xbfA file\n"),
+			Expected: []byte("\xef\xbb\xbfA file\n"),
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def := &editorconfig.Definition{Charset: tc.Charset}
+			filename := filepath.Join(t.TempDir(), "file.txt")
+
+			if err := os.WriteFile(filename, tc.File, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			// The second run must not change anything.
+			for i := 0; i < 2; i++ {
+				if errs := FixWithDefinition(ctx, def, filename); len(errs) != 0 {
+					t.Fatalf("run %d: no errors were expected, got %v", i+1, errs)
+				}
+
+				result, err := os.ReadFile(filename)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(tc.Expected, result) {
+					t.Errorf("run %d: diff %s", i+1, cmp.Diff(tc.Expected, result))
+				}
+			}
+
+			if errs := LintWithDefinition(ctx, def, filename); len(errs) != 0 {
+				t.Errorf("no errors were expected once fixed, got %v", errs)
+			}
+		})
+	}
+}
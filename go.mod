@@ -10,6 +10,7 @@ require (
 	github.com/karrick/godirwalk v1.17.0
 	github.com/logrusorgru/aurora v2.0.3+incompatible
 	github.com/mattn/go-colorable v0.1.13
+	github.com/pmezard/go-difflib v1.0.0
 	golang.org/x/term v0.8.0
 	golang.org/x/text v0.9.0
 	k8s.io/klog/v2 v2.100.1
@@ -20,7 +21,6 @@ require (
 	github.com/hashicorp/errwrap v1.0.0 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
 	github.com/mattn/go-isatty v0.0.16 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/mod v0.10.0 // indirect
 	golang.org/x/sys v0.8.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
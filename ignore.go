@@ -0,0 +1,322 @@
+package eclint
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+// IgnoreFileName is the file listing the paths eclint skips, using the
+// gitignore syntax.
+const IgnoreFileName = ".eclintignore"
+
+// ignoreRule is a pattern of an ignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreMatcher tells whether a path is ignored by the ignore files found in
+// its directory and the ones above, up to the root.
+//
+// As with git, the patterns are relative to the directory of their file, the
+// ones of the deeper files and the last ones winning. A file within an
+// ignored directory cannot be included again.
+type ignoreMatcher struct {
+	root  string
+	rules map[string][]ignoreRule
+}
+
+// newIgnoreMatcher creates the matcher of the ignore files under the root,
+// the working directory when the path is within it or the path itself.
+func newIgnoreMatcher(path string) (*ignoreMatcher, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get absolute path of %s: %w", path, err)
+	}
+
+	root := abs
+
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, abs); err == nil && !isOutside(rel) {
+			root = wd
+		}
+	}
+
+	return &ignoreMatcher{
+		root:  root,
+		rules: make(map[string][]ignoreRule),
+	}, nil
+}
+
+// isOutside tells whether the relative path goes above its base.
+func isOutside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ignored tells whether the file, or any of its parent directories, is ignored.
+func (m *ignoreMatcher) ignored(filename string, isDir bool) (bool, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return false, fmt.Errorf("cannot get absolute path of %s: %w", filename, err)
+	}
+
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || rel == "." || isOutside(rel) {
+		return false, nil //nolint:nilerr
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i := 1; i < len(parts); i++ {
+		ok, err := m.match(filepath.Join(m.root, filepath.Join(parts[:i]...)), true)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return m.match(abs, isDir)
+}
+
+// match applies the rules of the ignore files to the absolute path, ignoring
+// its parent directories.
+func (m *ignoreMatcher) match(abs string, isDir bool) (bool, error) {
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || rel == "." || isOutside(rel) {
+		return false, nil //nolint:nilerr
+	}
+
+	ignored := false
+	dir := m.root
+	parts := strings.Split(rel, string(filepath.Separator))
+
+	for i := range parts {
+		rules, err := m.load(dir)
+		if err != nil {
+			return false, err
+		}
+
+		name := strings.Join(parts[i:], "/")
+
+		for _, rule := range rules {
+			if (!rule.dirOnly || isDir) && rule.re.MatchString(name) {
+				ignored = !rule.negate
+			}
+		}
+
+		dir = filepath.Join(dir, parts[i])
+	}
+
+	return ignored, nil
+}
+
+// load reads the ignore file of the directory, once.
+func (m *ignoreMatcher) load(dir string) ([]ignoreRule, error) {
+	if rules, ok := m.rules[dir]; ok {
+		return rules, nil
+	}
+
+	rules, err := readIgnoreFile(filepath.Join(dir, IgnoreFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	m.rules[dir] = rules
+
+	return rules, nil
+}
+
+// readIgnoreFile parses the patterns of the ignore file, a missing file having none.
+func readIgnoreFile(filename string) ([]ignoreRule, error) {
+	fp, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s: %w", filename, err)
+	}
+
+	defer fp.Close()
+
+	rules := make([]ignoreRule, 0)
+
+	sc := bufio.NewScanner(fp)
+	for sc.Scan() {
+		rule, ok, err := parseIgnorePattern(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", filename, err)
+		}
+
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", filename, err)
+	}
+
+	return rules, nil
+}
+
+// parseIgnorePattern converts a line of the gitignore syntax into a rule,
+// the blank lines and the comments having none.
+func parseIgnorePattern(line string) (ignoreRule, bool, error) { //nolint:cyclop
+	rule := ignoreRule{}
+
+	line = strings.TrimSuffix(line, "\r")
+
+	// The trailing spaces are dropped unless escaped.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false, nil
+	}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	if line == "" {
+		return rule, false, nil
+	}
+
+	// A pattern with a slash, but the trailing one, is relative to the directory of the file.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(line); i++ {
+		atStart := i == 0 || line[i-1] == '/'
+
+		switch {
+		case atStart && strings.HasPrefix(line[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+
+			i += 2
+		case atStart && line[i:] == "**":
+			b.WriteString(".*")
+
+			i++
+		case line[i] == '*':
+			b.WriteString("[^/]*")
+		case line[i] == '?':
+			b.WriteString("[^/]")
+		case line[i] == '\\' && i+1 < len(line):
+			b.WriteString(regexp.QuoteMeta(line[i+1 : i+2]))
+
+			i++
+		case line[i] == '[':
+			// The closing bracket may be the first character of the class.
+			j := i + 1
+			if j < len(line) && (line[j] == '!' || line[j] == '^') {
+				j++
+			}
+
+			if j < len(line) && line[j] == ']' {
+				j++
+			}
+
+			end := strings.Index(line[j:], "]")
+			if end < 0 {
+				b.WriteString(`\[`)
+
+				continue
+			}
+
+			class := line[i+1 : j+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			b.WriteString("[" + strings.ReplaceAll(class, "[", `\[`) + "]")
+
+			i = j + end
+		default:
+			b.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		}
+	}
+
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return rule, false, fmt.Errorf("invalid pattern %q: %w", line, err)
+	}
+
+	rule.re = re
+
+	return rule, true, nil
+}
+
+// filterIgnoredContext drops the files ignored by the ignore files from the
+// channel (asynchronously).
+func filterIgnoredContext(
+	ctx context.Context,
+	m *ignoreMatcher,
+	fileChan <-chan string,
+	errChan <-chan error,
+) (<-chan string, <-chan error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	filesChan := make(chan string, 128)
+	errsChan := make(chan error, 1)
+
+	go func() {
+		defer close(filesChan)
+		defer close(errsChan)
+
+		for filename := range fileChan {
+			fi, err := os.Stat(filename)
+			isDir := err == nil && fi.IsDir()
+
+			ok, err := m.ignored(filename, isDir)
+			if err != nil {
+				errsChan <- err
+
+				return
+			}
+
+			if ok {
+				log.V(4).Info("skipped by "+IgnoreFileName, "filename", filename)
+
+				continue
+			}
+
+			select {
+			case filesChan <- filename:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err, ok := <-errChan; ok && err != nil {
+			errsChan <- err
+		}
+	}()
+
+	return filesChan, errsChan
+}
@@ -0,0 +1,167 @@
+package eclint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseIgnorePattern(t *testing.T) {
+	tests := []struct {
+		Pattern string
+		Name    string
+		IsDir   bool
+		Match   bool
+	}{
+		{Pattern: "*.min.js", Name: "a.min.js", Match: true},
+		{Pattern: "*.min.js", Name: "dist/a.min.js", Match: true},
+		{Pattern: "*.min.js", Name: "a.js"},
+		{Pattern: "/a.txt", Name: "a.txt", Match: true},
+		{Pattern: "/a.txt", Name: "sub/a.txt"},
+		{Pattern: "doc/*.txt", Name: "doc/a.txt", Match: true},
+		{Pattern: "doc/*.txt", Name: "doc/sub/a.txt"},
+		{Pattern: "doc/**/*.txt", Name: "doc/sub/a.txt", Match: true},
+		{Pattern: "doc/**/*.txt", Name: "doc/a.txt", Match: true},
+		{Pattern: "**/vendor", Name: "a/b/vendor", IsDir: true, Match: true},
+		{Pattern: "build/**", Name: "build/a/b", Match: true},
+		{Pattern: "build/**", Name: "build", IsDir: true},
+		{Pattern: "build/", Name: "build", IsDir: true, Match: true},
+		{Pattern: "build/", Name: "build"},
+		{Pattern: "file?.[ch]", Name: "file1.c", Match: true},
+		{Pattern: "file?.[!ch]", Name: "file1.c"},
+		{Pattern: `\#hash`, Name: "#hash", Match: true},
+		{Pattern: "space\\ ", Name: "space ", Match: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Pattern+" "+tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, ok, err := parseIgnorePattern(tc.Pattern)
+			if err != nil || !ok {
+				t.Fatalf("a rule was expected, got %v", err)
+			}
+
+			match := (!rule.dirOnly || tc.IsDir) && rule.re.MatchString(tc.Name)
+			if match != tc.Match {
+				t.Errorf("%q matching %q, %t was expected", tc.Pattern, tc.Name, tc.Match)
+			}
+		})
+	}
+}
+
+func TestParseIgnorePatternNone(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "!", "/"} {
+		if _, ok, err := parseIgnorePattern(line); err != nil || ok {
+			t.Errorf("no rules were expected for %q, got %t, %v", line, ok, err)
+		}
+	}
+}
+
+func TestWalkEclintIgnore(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		IgnoreFileName:                                  "*.min.js\n!keep.min.js\ngenerated/\n",
+		"a.js":                                          "",
+		"a.min.js":                                      "",
+		"keep.min.js":                                   "",
+		"local.txt":                                     "",
+		filepath.Join("generated", "a.js"):              "",
+		filepath.Join("sub", IgnoreFileName):            "local.txt\n/only-here.txt\n!b.min.js\n",
+		filepath.Join("sub", "b.min.js"):                "",
+		filepath.Join("sub", "local.txt"):               "",
+		filepath.Join("sub", "only-here.txt"):           "",
+		filepath.Join("sub", "deeper", "local.txt"):     "",
+		filepath.Join("sub", "deeper", "only-here.txt"): "",
+	}
+
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fileChan, errChan := WalkAllContext(ctx, dir)
+
+	found := make([]string, 0)
+
+	for filename := range fileChan {
+		if fi, err := os.Stat(filename); err == nil && fi.IsDir() {
+			continue
+		}
+
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found = append(found, filepath.ToSlash(rel))
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(found)
+
+	expected := []string{
+		".eclintignore",
+		"a.js",
+		"keep.min.js",
+		"local.txt",
+		"sub/.eclintignore",
+		"sub/b.min.js",
+		"sub/deeper/only-here.txt",
+	}
+
+	if diff := cmp.Diff(expected, found); diff != "" {
+		t.Errorf("unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func TestIgnoreMatcherParentDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFileName), []byte("generated/\n!*.go\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := newIgnoreMatcher(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A file within an ignored directory cannot be included again.
+	ok, err := m.ignored(filepath.Join(dir, "generated", "a.go"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok {
+		t.Error("the file within the ignored directory was expected to be ignored")
+	}
+
+	ok, err = m.ignored(filepath.Join(dir, "a.go"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok {
+		t.Error("the file was expected to be kept")
+	}
+}
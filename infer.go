@@ -0,0 +1,170 @@
+package eclint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// inferIndentKey is the context key enabling the inference of the indentation.
+type inferIndentKey struct{}
+
+// WithIndentInference returns a context where the indentation style and size
+// of the files without an indent_style are inferred from their content, the
+// lines deviating from the dominant indentation being reported.
+func WithIndentInference(ctx context.Context) context.Context {
+	return context.WithValue(ctx, inferIndentKey{}, true)
+}
+
+// inferIndentFromContext tells whether the indentation has to be inferred.
+func inferIndentFromContext(ctx context.Context) bool {
+	infer, _ := ctx.Value(inferIndentKey{}).(bool)
+
+	return infer
+}
+
+// indentUnitKey is the context key enabling the check of the indentation unit.
+type indentUnitKey struct{}
+
+// WithIndentUnitCheck returns a context where the smallest step between the
+// indentation of the consecutive lines has to be the indent_size, e.g. a
+// file consistently indented by 2 spaces breaks indent_size = 4 even on the
+// lines indented by 4 spaces.
+func WithIndentUnitCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, indentUnitKey{}, true)
+}
+
+// indentUnitFromContext tells whether the indentation unit has to be checked.
+func indentUnitFromContext(ctx context.Context) bool {
+	check, _ := ctx.Value(indentUnitKey{}).(bool)
+
+	return check
+}
+
+// indentUnit tracks the smallest step between the space indentation of the
+// consecutive lines, and the first line making it.
+type indentUnit struct {
+	previous int
+	size     int
+	line     []byte
+	index    int
+	position int
+	offset   int
+}
+
+// add measures the step between the indentation of the line, starting at the
+// offset, and the one of the previous line, the lines indented with tabs
+// being left to their rules.
+func (u *indentUnit) add(index int, offset int, data []byte) {
+	n := len(data) - len(bytes.TrimLeft(data, " "))
+	if n < len(data) && data[n] == tab {
+		return
+	}
+
+	step := n - u.previous
+	if step < 0 {
+		step = -step
+	}
+
+	if step > 0 && (u.size == 0 || step < u.size) {
+		u.size = step
+		u.line = data
+		u.index = index
+		u.position = n
+		u.offset = offset + n
+	}
+
+	u.previous = n
+}
+
+// check reports the first line evidencing a unit other than the indent_size.
+func (u *indentUnit) check(size int) error {
+	if u.size == 0 || u.size == size {
+		return nil
+	}
+
+	return ValidationError{
+		Rule:     RuleIndentSize,
+		Message:  fmt.Sprintf("the indentation unit is %d spaces, %d were expected", u.size, size),
+		Line:     u.line,
+		Index:    u.index,
+		Position: u.position,
+		Offset:   u.offset,
+	}
+}
+
+// inferIndentation picks the dominant indentation of the content.
+//
+// The style is the one starting the most lines, an empty one when no lines
+// are indented. The size of the space indentation is the most common step
+// between the consecutive lines, 0 when unknown.
+func inferIndentation(data []byte) (string, int) {
+	tabs := 0
+	spaces := 0
+	steps := make(map[int]int)
+	previous := 0
+
+	for _, line := range bytes.Split(data, []byte{lf}) {
+		if isBlankLine(line) {
+			continue
+		}
+
+		switch line[0] {
+		case tab:
+			tabs++
+
+			continue
+		case space:
+			spaces++
+		}
+
+		n := len(line) - len(bytes.TrimLeft(line, " "))
+
+		step := n - previous
+		if step < 0 {
+			step = -step
+		}
+
+		if step > 0 {
+			steps[step]++
+		}
+
+		previous = n
+	}
+
+	switch {
+	case tabs > spaces:
+		return TabValue, 0
+	case spaces == 0:
+		return "", 0
+	}
+
+	size := 0
+
+	for step, count := range steps {
+		if count > steps[size] || (count == steps[size] && step < size) {
+			size = step
+		}
+	}
+
+	return SpaceValue, size
+}
+
+// inferIndentation sets the indentation inferred from the content, the
+// indent_size being kept when set.
+func (def *definition) inferIndentation(data []byte) bool {
+	style, size := inferIndentation(data)
+	if style == "" {
+		return false
+	}
+
+	def.IndentStyle = style
+
+	if def.IndentSize == 0 {
+		def.IndentSize = size
+		def.Definition.IndentSize = strconv.Itoa(size)
+	}
+
+	return true
+}
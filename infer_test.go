@@ -0,0 +1,170 @@
+package eclint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+)
+
+func TestInferIndentation(t *testing.T) {
+	tests := []struct {
+		Name  string
+		File  []byte
+		Style string
+		Size  int
+	}{
+		{
+			Name:  "tabs",
+			File:  []byte("a {\n\tb\n\t\tc\n\t}\n"),
+			Style: TabValue,
+		}, {
+			Name:  "two spaces",
+			File:  []byte("a:\n  b:\n    c: 1\n  d: 2\n"),
+			Style: SpaceValue,
+			Size:  2,
+		}, {
+			Name:  "four spaces and an odd line",
+			File:  []byte("a\n    b\n        c\n     d\n    e\n"),
+			Style: SpaceValue,
+			Size:  4,
+		}, {
+			Name: "no indentation",
+			File: []byte("a\n\nb\n"),
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			style, size := inferIndentation(tc.File)
+			if style != tc.Style || size != tc.Size {
+				t.Errorf("%q %d was expected, got %q %d", tc.Style, tc.Size, style, size)
+			}
+		})
+	}
+}
+
+func TestInferIndentationLint(t *testing.T) {
+	file := []byte("func a() {\n\tif b {\n\t\tc()\n    }\n\treturn\n}\n")
+
+	def := &editorconfig.Definition{}
+
+	// Without the inference, nothing is checked.
+	errs := LintReaderWithDefinition(context.TODO(), def, "a.go", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 0 {
+		t.Fatalf("no errors were expected, got %v", errs)
+	}
+
+	ctx := WithIndentInference(context.TODO())
+
+	errs = LintReaderWithDefinition(ctx, def, "a.go", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %v", errs)
+	}
+
+	var ve ValidationError
+	if ok := errors.As(errs[0], &ve); !ok {
+		t.Fatalf("a validation error was expected, got %s", errs[0])
+	}
+
+	if ve.Rule != RuleIndentStyle || ve.Index != 3 {
+		t.Errorf("an indent style error was expected on the fourth line, got %s", ve)
+	}
+}
+
+func TestIndentUnit(t *testing.T) {
+	tests := []struct {
+		Name     string
+		File     []byte
+		Index    int
+		Position int
+	}{
+		{
+			Name:     "consistently two spaces",
+			File:     []byte("a:\n  b:\n    c: 1\n  d: 2\n"),
+			Index:    1,
+			Position: 2,
+		}, {
+			Name:     "two spaces within four",
+			File:     []byte("a:\n    b:\n      c: 1\n    d: 2\n"),
+			Index:    2,
+			Position: 6,
+		}, {
+			Name:     "eight spaces",
+			File:     []byte("a {\n        b\n                c\n        d\n}\n"),
+			Index:    1,
+			Position: 8,
+		}, {
+			Name:  "four spaces",
+			File:  []byte("a {\n    b\n        c\n    /*\n     * d\n     */\n}\n"),
+			Index: -1,
+		},
+	}
+
+	def := &editorconfig.Definition{
+		IndentStyle: SpaceValue,
+		IndentSize:  "4",
+		Raw: map[string]string{
+			"block_comment_start": "/*",
+			"block_comment":       "*",
+			"block_comment_end":   "*/",
+		},
+	}
+
+	ctx := WithIndentUnitCheck(context.TODO())
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := LintReaderWithDefinition(ctx, def, "a.txt", bytes.NewReader(tc.File), int64(len(tc.File)))
+
+			var unit *ValidationError
+
+			for _, err := range errs {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); ok && strings.HasPrefix(ve.Message, "the indentation unit") {
+					unit = &ve
+				}
+			}
+
+			if tc.Index < 0 {
+				if len(errs) != 0 {
+					t.Errorf("no errors were expected, got %v", errs)
+				}
+
+				return
+			}
+
+			if unit == nil {
+				t.Fatalf("an indentation unit error was expected, got %v", errs)
+			}
+
+			if unit.Rule != RuleIndentSize || unit.Index != tc.Index || unit.Position != tc.Position {
+				t.Errorf("%s error expected at %d:%d, got %s", RuleIndentSize, tc.Index, tc.Position, unit)
+			}
+		})
+	}
+}
+
+func TestIndentUnitDisabled(t *testing.T) {
+	file := []byte("a {\n        b\n                c\n        d\n}\n")
+
+	def := &editorconfig.Definition{
+		IndentStyle: SpaceValue,
+		IndentSize:  "4",
+	}
+
+	errs := LintReaderWithDefinition(context.TODO(), def, "a.txt", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 0 {
+		t.Errorf("no errors were expected without the check, got %v", errs)
+	}
+}
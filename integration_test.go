@@ -2,9 +2,12 @@ package eclint_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/editorconfig/editorconfig-core-go/v2"
 	"gitlab.com/greut/eclint"
 )
 
@@ -18,6 +21,46 @@ func TestLintSimple(t *testing.T) {
 	}
 }
 
+func TestLintReader(t *testing.T) {
+	ctx := context.TODO()
+
+	def, err := editorconfig.GetDefinitionForFilename("testdata/simple/stdin.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "Hello\r\n World!\r\nBye \r\n"
+
+	errs := eclint.LintReaderWithDefinition(ctx, def, "stdin.txt", strings.NewReader(content), int64(len(content)))
+	if len(errs) != 2 {
+		t.Fatalf("two errors were expected, got %d", len(errs))
+	}
+
+	for _, err := range errs {
+		if !strings.HasPrefix(err.Error(), "stdin.txt:") {
+			t.Errorf("the error should be reported on the virtual filename, got %s", err)
+		}
+	}
+}
+
+func TestLintReaderDefinition(t *testing.T) {
+	ctx := context.TODO()
+
+	errs := eclint.LintReader(ctx, "testdata/simple/stdin.txt", strings.NewReader("Hello\nWorld\r\n"))
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %d", len(errs))
+	}
+
+	var ve eclint.ValidationError
+	if ok := errors.As(errs[0], &ve); !ok {
+		t.Fatalf("a validation error was expected, got %s", errs[0])
+	}
+
+	if ve.Rule != eclint.RuleEndOfLine || ve.Index != 0 {
+		t.Errorf("an end of line error was expected on the first line, got %s", ve)
+	}
+}
+
 func TestLintMissing(t *testing.T) {
 	ctx := context.TODO()
 
@@ -71,6 +114,23 @@ func TestBlockCommentInvalidSpec(t *testing.T) {
 	}
 }
 
+func TestLintUnset(t *testing.T) {
+	ctx := context.TODO()
+
+	// The parent section applies.
+	errs := eclint.Lint(ctx, "./testdata/unset/a.txt")
+	if len(errs) != 2 {
+		t.Errorf("two errors were expected, got %v", errs)
+	}
+
+	// The child section unsets every property.
+	for _, err := range eclint.Lint(ctx, "./testdata/unset/sub/a.txt") {
+		if err != nil {
+			t.Errorf("no errors where expected, got %s", err)
+		}
+	}
+}
+
 func TestLintCharset(t *testing.T) {
 	ctx := context.TODO()
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 
 	"github.com/editorconfig/editorconfig-core-go/v2"
 	"github.com/go-logr/logr"
@@ -30,9 +31,13 @@ const (
 	Utf8 = "utf-8"
 	// Latin1 is the legacy 7-bits character set.
 	Latin1 = "latin1"
+	// ASCII is the 7-bit character set, a custom charset value forbidding any byte above 0x7f.
+	ASCII = "ascii"
 )
 
 // Lint does the hard work of validating the given file.
+//
+// The errors found in the file are of the ValidationError type.
 func Lint(ctx context.Context, filename string) []error {
 	def, err := editorconfig.GetDefinitionForFilename(filename)
 	if err != nil {
@@ -42,11 +47,29 @@ func Lint(ctx context.Context, filename string) []error {
 	return LintWithDefinition(ctx, def, filename)
 }
 
+// LintReader validates the content of the reader as if it was the given file.
+//
+// The EditorConfig definition is resolved using the filename, which doesn't
+// have to exist.
+func LintReader(ctx context.Context, filename string, r io.Reader) []error {
+	def, err := editorconfig.GetDefinitionForFilename(filename)
+	if err != nil {
+		return []error{fmt.Errorf("cannot open file %s. %w", filename, err)}
+	}
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return []error{fmt.Errorf("cannot read %s. %w", filename, err)}
+	}
+
+	return LintReaderWithDefinition(ctx, def, filename, bytes.NewReader(buf), int64(len(buf)))
+}
+
 // LintWithDefinition does the hard work of validating the given file.
-func LintWithDefinition(ctx context.Context, d *editorconfig.Definition, filename string) []error { //nolint:funlen
+func LintWithDefinition(ctx context.Context, d *editorconfig.Definition, filename string) []error {
 	log := logr.FromContextOrDiscard(ctx)
 
-	def, err := newDefinition(d)
+	def, err := newDefinitionContext(ctx, d)
 	if err != nil {
 		return []error{err}
 	}
@@ -84,11 +107,62 @@ func LintWithDefinition(ctx context.Context, d *editorconfig.Definition, filenam
 		return nil
 	}
 
-	charset, isBinary, err := ProbeCharsetOrBinary(ctx, r, def.Charset)
+	if decompressFromContext(ctx) {
+		zr, closer, err := decompress(r)
+		if err != nil {
+			return []error{fmt.Errorf("cannot read %s. %w", filename, err)}
+		}
+
+		if closer != nil {
+			defer closer.Close()
+
+			log.V(2).Info("gzip file decompressed", "filename", filename)
+
+			// The size of the content is unknown.
+			r = zr
+			fileSize = -1
+		}
+	}
+
+	return lintReader(ctx, def, filename, r, fileSize)
+}
+
+// LintReaderWithDefinition validates the content of the reader as if it was the given file.
+//
+// The filename is only used to report the errors, the definition must
+// have been resolved beforehand.
+func LintReaderWithDefinition(
+	ctx context.Context,
+	d *editorconfig.Definition,
+	filename string,
+	r io.Reader,
+	fileSize int64,
+) []error {
+	def, err := newDefinitionContext(ctx, d)
 	if err != nil {
 		return []error{err}
 	}
 
+	return lintReader(ctx, def, filename, bufio.NewReader(r), fileSize)
+}
+
+// lintReader probes the charset of the reader before validating its content.
+func lintReader(ctx context.Context, def *definition, filename string, r *bufio.Reader, fileSize int64) []error {
+	log := logr.FromContextOrDiscard(ctx)
+
+	charset, isBinary, err := ProbeCharsetOrBinary(ctx, r, expectedCharset(ctx, def))
+	if err != nil {
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			ve.Filename = filename
+			ve.Severity = severity(ctx, ve.Rule)
+
+			return []error{ve}
+		}
+
+		return []error{err}
+	}
+
 	if isBinary {
 		log.V(2).Info("binary file detected and skipped")
 
@@ -97,6 +171,10 @@ func LintWithDefinition(ctx context.Context, d *editorconfig.Definition, filenam
 
 	log.V(2).Info("charset probed", "filename", filename, "charset", charset)
 
+	if def.hasRedundantTabWidth() {
+		log.V(2).Info("tab_width has no effect with indent_style = space", "filename", filename, "tab_width", def.TabWidth)
+	}
+
 	var decoder *encoding.Decoder
 
 	switch charset {
@@ -104,11 +182,46 @@ func LintWithDefinition(ctx context.Context, d *editorconfig.Definition, filenam
 		decoder = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()
 	case "utf-16le":
 		decoder = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()
+	case "", UnsetValue, Utf8, "utf-8 bom", Latin1, ASCII:
+		// Keep the bytes as is for the charset rule and the offsets.
+		decoder = encoding.Nop.NewDecoder()
 	default:
 		decoder = unicode.UTF8.NewDecoder()
 	}
 
-	t := transform.NewReader(r, unicode.BOMOverride(decoder))
+	// The offsets count the bytes of the file, including the BOM stripped here.
+	bom := leadingBOM(r)
+
+	switch cs := detectCharsetUsingBOM(bom); cs {
+	case "utf-8 bom":
+		// Otherwise, the BOM override would decode the utf-8 content.
+		if _, err := r.Discard(len(bom)); err != nil {
+			return []error{fmt.Errorf("cannot read %s. %w", filename, err)}
+		}
+	case "utf-16be", "utf-16le":
+		charset = cs
+	}
+
+	var t io.Reader = transform.NewReader(r, unicode.BOMOverride(decoder))
+
+	if inferIndentFromContext(ctx) && (def.IndentStyle == "" || def.IndentStyle == UnsetValue) {
+		buf, err := io.ReadAll(t)
+		if err != nil {
+			return []error{fmt.Errorf("cannot read %s. %w", filename, err)}
+		}
+
+		if def.inferIndentation(buf) {
+			log.V(2).Info(
+				"indentation inferred",
+				"filename", filename,
+				"indent_style", def.IndentStyle,
+				"indent_size", def.IndentSize,
+			)
+		}
+
+		t = bytes.NewReader(buf)
+	}
+
 	errs := validate(ctx, t, fileSize, charset, def)
 
 	// Enrich the errors with the filename
@@ -116,6 +229,8 @@ func LintWithDefinition(ctx context.Context, d *editorconfig.Definition, filenam
 		var ve ValidationError
 		if ok := errors.As(err, &ve); ok {
 			ve.Filename = filename
+			ve.Severity = severity(ctx, ve.Rule)
+			ve.Offset += len(bom)
 			errs[i] = ve
 		} else if err != nil {
 			errs[i] = err
@@ -133,24 +248,120 @@ func validate( //nolint:cyclop,gocognit,funlen
 	charset string,
 	def *definition,
 ) []error {
-	return ReadLines(r, fileSize, func(index int, data []byte, isEOF bool) error {
+	ruleSet := rulesFromContext(ctx)
+
+	// lines counts the lines read, the next one may be too long to be read.
+	lines := 0
+
+	// offset counts the bytes of the lines read, as found in the file.
+	offset := 0
+
+	// size gives the number of bytes the decoded data takes in the file.
+	size := func(data []byte) int {
+		return len(data)
+	}
+
+	if charset == "utf-16be" || charset == "utf-16le" {
+		size = utf16Size
+	}
+
+	// offsetAt gives the offset within the file of the position within the line.
+	offsetAt := func(lineOffset int, data []byte, position int) int {
+		if position > len(data) {
+			position = len(data)
+		}
+
+		return lineOffset + size(data[:position])
+	}
+
+	// extra are the errors on top of the one of each line, e.g. every tab of a line.
+	extra := make([]ValidationError, 0)
+
+	// The indentation unit is known once every line was read.
+	var unit *indentUnit
+	if indentUnitFromContext(ctx) && def.IndentStyle == SpaceValue && def.IndentSize > 0 {
+		unit = &indentUnit{}
+	}
+
+	// Without an end_of_line, the lines may have to end like the first one.
+	consistentEndOfLine := eolConsistencyFromContext(ctx) && (def.EndOfLine == "" || def.EndOfLine == UnsetValue)
+	firstEndOfLine := ""
+
+	errs := ReadLinesContext(ctx, r, fileSize, func(index int, data []byte, isEOF bool) error {
 		var err error
 
+		lines = index + 1
+
+		lineOffset := offset
+		offset += size(data)
+
 		if ctx.Err() != nil {
 			return fmt.Errorf("read lines got interrupted: %w", ctx.Err())
 		}
 
-		if isEOF {
-			if def.InsertFinalNewline != nil {
-				err = checkInsertFinalNewline(data, *def.InsertFinalNewline)
+		// The rules may be suppressed on this line by an inline directive.
+		rules := ruleSet
+		if suppressed := def.suppressedRules(data); len(suppressed) > 0 {
+			rules = ruleSet.without(suppressed)
+		}
+
+		insideBlockComment := def.InsideBlockComment
+
+		// The content of a block string, up to its closing line, isn't indented like the code.
+		insideBlockString := def.InsideBlockString
+		if def.BlockStringStart != nil {
+			def.InsideBlockString = isInsideBlockString(def.BlockStringStart, def.BlockStringEnd, insideBlockString, data)
+		}
+
+		if isEOF && def.InsertFinalNewline != nil && rules.enabled(RuleFinalNewline) {
+			err = checkInsertFinalNewline(data, *def.InsertFinalNewline)
+		}
+
+		// The final newline, when present, follows the end of line too.
+		if err == nil && def.EndOfLine != "" && def.EndOfLine != UnsetValue && rules.enabled(RuleEndOfLine) {
+			err = endOfLine(def.EndOfLine, data)
+		}
+
+		if consistentEndOfLine && hasEndOfLine(data) {
+			if firstEndOfLine == "" {
+				firstEndOfLine = endOfLineName(LineEnding(data))
+			} else if err == nil && rules.enabled(RuleEndOfLine) {
+				err = endOfLine(firstEndOfLine, data)
+
+				var ve ValidationError
+				if ok := errors.As(err, &ve); ok {
+					ve.Message += ", like the first line"
+					err = ve
+				}
 			}
-		} else {
-			if def.EndOfLine != "" && def.EndOfLine != UnsetValue {
-				err = endOfLine(def.EndOfLine, data)
+		}
+
+		if err == nil && def.Charset != "" && def.Charset != UnsetValue && rules.enabled(RuleByteOrderMark) {
+			err = checkByteOrderMark(index, data)
+		}
+
+		if err == nil && rules.enabled(RuleCharset) {
+			switch def.Charset {
+			case Utf8, "utf-8 bom":
+				err = checkUTF8(data)
+			case Latin1:
+				err = checkLatin1(data)
+			case ASCII:
+				err = checkASCII(data)
 			}
 		}
 
+		// The mixed indentation replaces the indentation errors of its line.
+		if err == nil &&
+			!insideBlockComment &&
+			!insideBlockString &&
+			rules.enabled(RuleMixedIndentation) &&
+			(def.IndentStyle == SpaceValue || def.IndentStyle == TabValue) {
+			err = checkMixedIndentation(def.IndentStyle, data)
+		}
+
 		if err == nil && //nolint:nestif
+			!insideBlockString &&
 			def.IndentStyle != "" &&
 			def.IndentStyle != UnsetValue &&
 			def.Definition.IndentSize != UnsetValue {
@@ -159,24 +370,85 @@ func validate( //nolint:cyclop,gocognit,funlen
 				// The indentation may fail within a block comment.
 				var ve ValidationError
 				if ok := errors.As(err, &ve); ok {
-					err = checkBlockComment(ve.Position, def.BlockComment, data)
+					err = checkBlockComment(def.IndentStyle, def.IndentSize, def.BlockComment, data)
+				}
+			} else if err != nil && !def.InsideBlockComment && hasIndentedPrefix(def.LineComments, data) {
+				// The line comments may be aligned with anything, only the style matters.
+				var ve ValidationError
+				if ok := errors.As(err, &ve); ok && ve.Rule == RuleIndentSize {
+					err = nil
+				}
+			} else if err != nil && !def.InsideBlockComment && hasIndentedPrefix(def.HalfIndentPrefixes, data) {
+				// The case labels may be indented by half the indent_size.
+				var ve ValidationError
+				if ok := errors.As(err, &ve); ok && ve.Rule == RuleIndentSize &&
+					indentStyle(def.IndentStyle, def.IndentSize/2, data) == nil {
+					err = nil
 				}
 			}
 
+			// The block comments are tracked even when their rules are disabled.
+			err = rules.filter(err)
+
 			if def.InsideBlockComment && def.BlockCommentEnd != nil {
 				def.InsideBlockComment = !isBlockCommentEnd(def.BlockCommentEnd, data)
 			}
 
-			if err == nil && !def.InsideBlockComment && def.BlockCommentStart != nil {
-				def.InsideBlockComment = isBlockCommentStart(def.BlockCommentStart, data)
+			if err == nil && !def.InsideBlockComment {
+				for _, markers := range def.BlockComments {
+					if isBlockCommentStart(markers.Start, data) {
+						def.useBlockComment(markers)
+						// A block comment may end on the line it started.
+						def.InsideBlockComment = !isBlockCommentEnd(markers.End, data)
+
+						break
+					}
+				}
 			}
 		}
 
-		if err == nil && def.TrimTrailingWhitespace != nil && *def.TrimTrailingWhitespace {
+		if unit != nil &&
+			!insideBlockComment &&
+			!insideBlockString &&
+			!isBlankLine(data) &&
+			!hasIndentedPrefix(def.LineComments, data) &&
+			!hasIndentedPrefix(def.HalfIndentPrefixes, data) &&
+			rules.enabled(RuleIndentSize) {
+			unit.add(index, lineOffset, data)
+		}
+
+		if def.ForbidTabs &&
+			!insideBlockString &&
+			def.IndentStyle == SpaceValue &&
+			rules.enabled(RuleTabCharacter) {
+			// The tabs of an indentation already reported aren't reported twice.
+			indentation := 0
+
+			var ie ValidationError
+			if ok := errors.As(err, &ie); ok && (ie.Rule == RuleIndentStyle || ie.Rule == RuleMixedIndentation) {
+				indentation = len(data) - len(bytes.TrimLeft(data, " \t"))
+			}
+
+			for _, ve := range checkTabCharacters(data) {
+				if ve.Position < indentation {
+					continue
+				}
+
+				ve.Line = data
+				ve.Index = index
+				ve.Offset = offsetAt(lineOffset, data, ve.Position)
+				extra = append(extra, ve)
+			}
+		}
+
+		if err == nil &&
+			def.TrimTrailingWhitespace != nil &&
+			*def.TrimTrailingWhitespace &&
+			rules.enabled(RuleTrailingWhitespace) {
 			err = checkTrimTrailingWhitespace(data)
 		}
 
-		if err == nil && def.MaxLength > 0 {
+		if err == nil && def.MaxLength > 0 && rules.enabled(RuleMaxLineLength) {
 			// Remove any BOM from the first line.
 			d := data
 			if index == 0 && charset != "" {
@@ -191,15 +463,132 @@ func validate( //nolint:cyclop,gocognit,funlen
 			err = MaxLineLength(def.MaxLength, def.TabWidth, d)
 		}
 
+		if def.MaxBlankLines >= 0 {
+			if isBlankLine(data) {
+				def.BlankLines++
+			} else {
+				def.BlankLines = 0
+			}
+
+			// The blank line may have another error, e.g. a trailing whitespace.
+			var ve ValidationError
+			if ok := errors.As(checkMaxConsecutiveBlankLines(def.MaxBlankLines, def.BlankLines), &ve); ok &&
+				rules.enabled(RuleBlankLines) {
+				ve.Line = data
+				ve.Index = index
+				ve.Offset = lineOffset
+				extra = append(extra, ve)
+			}
+		}
+
+		if def.TrimTrailingBlankLines {
+			// Remember the first line of the current run of blank lines.
+			if !isBlankLine(data) {
+				def.LastLine = nil
+			} else if def.LastLine == nil {
+				def.LastLine = data
+				def.LastIndex = index
+				def.LastOffset = lineOffset
+			}
+
+			// The error points to an earlier line, it's already enriched.
+			var ve ValidationError
+			if ok := errors.As(checkTrimTrailingBlankLines(def.LastIndex, def.LastOffset, def.LastLine), &ve); ok &&
+				isEOF &&
+				rules.enabled(RuleTrailingBlankLines) {
+				extra = append(extra, ve)
+			}
+		}
+
 		// Enrich the error with the line number
 		var ve ValidationError
 		if ok := errors.As(err, &ve); ok {
 			ve.Line = data
 			ve.Index = index
+			ve.Offset = offsetAt(lineOffset, data, ve.Position)
 
 			return ve
 		}
 
 		return err
 	})
+
+	n := 0
+
+	for _, err := range errs {
+		if errors.Is(err, bufio.ErrTooLong) {
+			if !ruleSet.enabled(RuleMaxLineBytes) {
+				continue
+			}
+
+			err = ValidationError{
+				Rule:    RuleMaxLineBytes,
+				Message: fmt.Sprintf("line is longer than the %d bytes that can be read", maxLineSizeFromContext(ctx)),
+				Index:   lines,
+				Offset:  offset,
+			}
+		}
+
+		errs[n] = err
+		n++
+	}
+
+	errs = errs[:n]
+
+	if unit != nil {
+		var ve ValidationError
+		if ok := errors.As(unit.check(def.IndentSize), &ve); ok {
+			extra = append(extra, ve)
+		}
+	}
+
+	return mergeByLine(errs, extra)
+}
+
+// mergeByLine inserts the extra errors among the errors of the lines, the
+// ones of the same line coming after.
+func mergeByLine(errs []error, extra []ValidationError) []error {
+	if len(extra) == 0 {
+		return errs
+	}
+
+	sort.SliceStable(extra, func(i, j int) bool {
+		return extra[i].Index < extra[j].Index
+	})
+
+	merged := make([]error, 0, len(errs)+len(extra))
+
+	for _, err := range errs {
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			for len(extra) > 0 && extra[0].Index < ve.Index {
+				merged = append(merged, extra[0])
+				extra = extra[1:]
+			}
+		}
+
+		merged = append(merged, err)
+	}
+
+	for _, ve := range extra {
+		merged = append(merged, ve)
+	}
+
+	return merged
+}
+
+// utf16Size counts the bytes of the utf-8 data once encoded in utf-16.
+func utf16Size(data []byte) int {
+	n := 0
+
+	for _, r := range string(data) {
+		if r > 0xffff {
+			// A surrogate pair
+			n += 4
+		} else {
+			n += 2
+		}
+	}
+
+	return n
 }
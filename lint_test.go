@@ -3,6 +3,8 @@ package eclint
 import (
 	"bytes"
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/editorconfig/editorconfig-core-go/v2"
@@ -49,7 +51,7 @@ without a final newline.`),
 			}
 
 			r := bytes.NewReader(tc.File)
-			for _, err := range validate(ctx, r, -1, "utf-8", def) {
+			for _, err := range validate(ctx, r, int64(len(tc.File)), "utf-8", def) {
 				if err != nil {
 					t.Errorf("no errors where expected, got %s", err)
 				}
@@ -70,7 +72,7 @@ without a final newline.`),
 
 			r := bytes.NewReader(tc.File)
 
-			for _, err := range validate(ctx, r, -1, "utf-8", def) {
+			for _, err := range validate(ctx, r, int64(len(tc.File)), "utf-8", def) {
 				if err == nil {
 					t.Error("an error was expected")
 				}
@@ -79,25 +81,1645 @@ without a final newline.`),
 	}
 }
 
+func TestEndOfLineWithoutFinalNewline(t *testing.T) {
+	yes := true
+
+	tests := []struct {
+		Name               string
+		InsertFinalNewline *bool
+		File               []byte
+		Rules              []string
+	}{
+		{
+			Name: "insert_final_newline unset",
+			File: []byte("a\nb"),
+		}, {
+			Name:               "insert_final_newline",
+			InsertFinalNewline: &yes,
+			File:               []byte("a\nb"),
+			Rules:              []string{RuleFinalNewline},
+		}, {
+			Name:  "wrong end of line",
+			File:  []byte("a\r\nb"),
+			Rules: []string{RuleEndOfLine},
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				EndOfLine:          "lf",
+				InsertFinalNewline: tc.InsertFinalNewline,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := validate(ctx, bytes.NewReader(tc.File), int64(len(tc.File)), "utf-8", def)
+			if len(errs) != len(tc.Rules) {
+				t.Fatalf("%d errors were expected, got %v", len(tc.Rules), errs)
+			}
+
+			for i, err := range errs {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); !ok || ve.Rule != tc.Rules[i] {
+					t.Errorf("a %s error was expected, got %s", tc.Rules[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestInsertFinalNewlineLastLine(t *testing.T) {
+	tests := []struct {
+		Name               string
+		InsertFinalNewline bool
+		File               []byte
+		Index              int
+		Position           int
+	}{
+		{
+			Name:               "empty line with a newline",
+			InsertFinalNewline: true,
+			File:               []byte("a\n\n"),
+			Index:              -1,
+		}, {
+			Name:               "empty line with an extraneous newline",
+			InsertFinalNewline: false,
+			File:               []byte("a\n\n"),
+			Index:              1,
+			Position:           0,
+		}, {
+			Name:               "line with a newline",
+			InsertFinalNewline: true,
+			File:               []byte("a\n"),
+			Index:              -1,
+		}, {
+			Name:               "line with an extraneous newline",
+			InsertFinalNewline: false,
+			File:               []byte("a\n"),
+			Index:              0,
+			Position:           1,
+		}, {
+			Name:               "blank line without a newline",
+			InsertFinalNewline: true,
+			File:               []byte("a\n\n "),
+			Index:              2,
+			Position:           1,
+		}, {
+			Name:               "blank line without an extraneous newline",
+			InsertFinalNewline: false,
+			File:               []byte("a\n\n "),
+			Index:              -1,
+		}, {
+			Name:               "line without a newline",
+			InsertFinalNewline: true,
+			File:               []byte("a\na"),
+			Index:              1,
+			Position:           1,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				InsertFinalNewline: &tc.InsertFinalNewline,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := validate(ctx, bytes.NewReader(tc.File), int64(len(tc.File)), "utf-8", def)
+			if tc.Index < 0 {
+				if len(errs) != 0 {
+					t.Fatalf("no errors were expected, got %v", errs)
+				}
+
+				return
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("one error was expected, got %v", errs)
+			}
+
+			var ve ValidationError
+			if ok := errors.As(errs[0], &ve); !ok {
+				t.Fatalf("a validation error was expected, got %s", errs[0])
+			}
+
+			if ve.Rule != RuleFinalNewline || ve.Index != tc.Index || ve.Position != tc.Position {
+				t.Errorf(
+					"%s error expected at %d:%d, got %s at %d:%d",
+					RuleFinalNewline, tc.Index, tc.Position, ve.Rule, ve.Index, ve.Position,
+				)
+			}
+		})
+	}
+}
+
+func TestInsertFinalNewlineFalse(t *testing.T) {
+	tests := []struct {
+		Name     string
+		File     []byte
+		Errors   int
+		Position int
+	}{
+		{
+			Name:     "lf",
+			File:     []byte("A file\nwith a final lf\n"),
+			Errors:   1,
+			Position: 15,
+		}, {
+			Name:     "crlf",
+			File:     []byte("A file\r\nwith a final crlf\r\n"),
+			Errors:   1,
+			Position: 17,
+		}, {
+			Name:     "cr",
+			File:     []byte("A file\rwith a final cr\r"),
+			Errors:   1,
+			Position: 15,
+		}, {
+			Name: "no final newline",
+			File: []byte("A file\nwithout a final newline"),
+		}, {
+			Name: "empty file",
+			File: []byte(""),
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			insertFinalNewline := false
+			def, err := newDefinition(&editorconfig.Definition{
+				InsertFinalNewline: &insertFinalNewline,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if len(errs) != tc.Errors {
+				t.Fatalf("%d errors were expected, got %d", tc.Errors, len(errs))
+			}
+
+			for _, err := range errs {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); !ok {
+					t.Fatalf("a validation error was expected, got %s", err)
+				}
+
+				if ve.Index != 1 || ve.Position != tc.Position {
+					t.Errorf("error expected at 1:%d, got %d:%d", tc.Position, ve.Index, ve.Position)
+				}
+			}
+		})
+	}
+}
+
+func TestInsertFinalNewlineCRLF(t *testing.T) {
+	tests := []struct {
+		Name               string
+		InsertFinalNewline bool
+		File               []byte
+		Rule               string
+		Index              int
+		Position           int
+	}{
+		{
+			Name:               "final crlf",
+			InsertFinalNewline: true,
+			File:               []byte("Hello\r\nWorld\r\n"),
+		}, {
+			Name:               "missing final crlf",
+			InsertFinalNewline: true,
+			File:               []byte("Hello\r\nWorld"),
+			Rule:               RuleFinalNewline,
+			Index:              1,
+			Position:           5,
+		}, {
+			Name:               "one line with a final crlf",
+			InsertFinalNewline: true,
+			File:               []byte("Hello\r\n"),
+		}, {
+			Name:               "one line missing its final crlf",
+			InsertFinalNewline: true,
+			File:               []byte("Hello"),
+			Rule:               RuleFinalNewline,
+			Index:              0,
+			Position:           5,
+		}, {
+			Name:               "final lf",
+			InsertFinalNewline: true,
+			File:               []byte("Hello\r\nWorld\n"),
+			Rule:               RuleEndOfLine,
+			Index:              1,
+			Position:           5,
+		}, {
+			Name:               "final cr",
+			InsertFinalNewline: true,
+			File:               []byte("Hello\r\nWorld\r"),
+			Rule:               RuleEndOfLine,
+			Index:              1,
+			Position:           5,
+		}, {
+			Name:               "no final crlf",
+			InsertFinalNewline: false,
+			File:               []byte("Hello\r\nWorld"),
+		}, {
+			Name:               "extraneous final crlf",
+			InsertFinalNewline: false,
+			File:               []byte("Hello\r\nWorld\r\n"),
+			Rule:               RuleFinalNewline,
+			Index:              1,
+			Position:           5,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				EndOfLine:          "crlf",
+				InsertFinalNewline: &tc.InsertFinalNewline,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if tc.Rule == "" {
+				if len(errs) != 0 {
+					t.Fatalf("no errors were expected, got %v", errs)
+				}
+
+				return
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("one error was expected, got %v", errs)
+			}
+
+			var ve ValidationError
+			if ok := errors.As(errs[0], &ve); !ok {
+				t.Fatalf("a validation error was expected, got %s", errs[0])
+			}
+
+			if ve.Rule != tc.Rule || ve.Index != tc.Index || ve.Position != tc.Position {
+				t.Errorf(
+					"%s error expected at %d:%d, got %s at %d:%d",
+					tc.Rule, tc.Index, tc.Position, ve.Rule, ve.Index, ve.Position,
+				)
+			}
+		})
+	}
+}
+
+func TestNewDefinitionIndentSize(t *testing.T) {
+	tests := []struct {
+		Name       string
+		IndentSize string
+		TabWidth   int
+		Expected   definition
+	}{
+		{
+			Name:       "tab using tab_width",
+			IndentSize: "tab",
+			TabWidth:   4,
+			Expected:   definition{IndentSize: 4, TabWidth: 4},
+		}, {
+			Name:       "tab without tab_width",
+			IndentSize: "tab",
+			Expected:   definition{IndentSize: DefaultTabWidth},
+		}, {
+			Name:       "tab_width defaults to indent_size",
+			IndentSize: "2",
+			Expected:   definition{IndentSize: 2, TabWidth: 2},
+		}, {
+			Name:       "tab_width",
+			IndentSize: "2",
+			TabWidth:   8,
+			Expected:   definition{IndentSize: 2, TabWidth: 8},
+		}, {
+			Name:       "unset",
+			IndentSize: "unset",
+			TabWidth:   4,
+			Expected:   definition{TabWidth: 4},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentSize: tc.IndentSize,
+				TabWidth:   tc.TabWidth,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if def.IndentSize != tc.Expected.IndentSize || def.TabWidth != tc.Expected.TabWidth {
+				t.Errorf(
+					"indent size %d and tab width %d were expected, got %d and %d",
+					tc.Expected.IndentSize, tc.Expected.TabWidth, def.IndentSize, def.TabWidth,
+				)
+			}
+		})
+	}
+}
+
+func TestEmptyFile(t *testing.T) {
+	yes := true
+	no := false
+
+	tests := []struct {
+		Name       string
+		Definition *editorconfig.Definition
+	}{
+		{
+			Name:       "insert_final_newline = true",
+			Definition: &editorconfig.Definition{InsertFinalNewline: &yes},
+		}, {
+			Name:       "insert_final_newline = false",
+			Definition: &editorconfig.Definition{InsertFinalNewline: &no},
+		}, {
+			Name:       "charset = utf-8",
+			Definition: &editorconfig.Definition{Charset: Utf8},
+		}, {
+			Name:       "charset = utf-8-bom",
+			Definition: &editorconfig.Definition{Charset: "utf-8-bom"},
+		}, {
+			Name:       "charset = latin1",
+			Definition: &editorconfig.Definition{Charset: Latin1},
+		}, {
+			Name:       "charset = utf-16le",
+			Definition: &editorconfig.Definition{Charset: "utf-16le"},
+		}, {
+			Name:       "end_of_line = crlf",
+			Definition: &editorconfig.Definition{EndOfLine: "crlf"},
+		}, {
+			Name:       "indent_style = tab",
+			Definition: &editorconfig.Definition{IndentStyle: TabValue},
+		}, {
+			Name:       "trim_trailing_whitespace = true",
+			Definition: &editorconfig.Definition{TrimTrailingWhitespace: &yes},
+		}, {
+			Name: "max_line_length = 1",
+			Definition: &editorconfig.Definition{
+				Raw: map[string]string{"max_line_length": "1"},
+			},
+		}, {
+			Name: "max_consecutive_blank_lines = 0",
+			Definition: &editorconfig.Definition{
+				Raw: map[string]string{"max_consecutive_blank_lines": "0"},
+			},
+		}, {
+			Name: "trim_trailing_blank_lines = true",
+			Definition: &editorconfig.Definition{
+				Raw: map[string]string{"trim_trailing_blank_lines": "true"},
+			},
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := LintReaderWithDefinition(ctx, tc.Definition, "empty.txt", bytes.NewReader(nil), 0)
+			if len(errs) != 0 {
+				t.Errorf("no errors were expected, got %v", errs)
+			}
+		})
+	}
+}
+
+func TestLongLine(t *testing.T) {
+	file := []byte("a\n" + strings.Repeat("b", 2*1024*1024) + "\nc\n")
+
+	def, err := newDefinition(&editorconfig.Definition{
+		EndOfLine: "lf",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := validate(context.TODO(), bytes.NewReader(file), int64(len(file)), "utf-8", def)
+	if len(errs) != 0 {
+		t.Errorf("no errors were expected, got %v", errs)
+	}
+
+	ctx := WithMaxLineSize(context.TODO(), 1024*1024)
+
+	errs = validate(ctx, bytes.NewReader(file), int64(len(file)), "utf-8", def)
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %v", errs)
+	}
+
+	var ve ValidationError
+	if ok := errors.As(errs[0], &ve); !ok || ve.Rule != RuleMaxLineBytes || ve.Index != 1 {
+		t.Errorf("a %s error on the second line was expected, got %v", RuleMaxLineBytes, errs[0])
+	}
+
+	ctx, err = WithRules(ctx, nil, []string{RuleMaxLineBytes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs = validate(ctx, bytes.NewReader(file), int64(len(file)), "utf-8", def)
+	if len(errs) != 0 {
+		t.Errorf("no errors were expected once disabled, got %v", errs)
+	}
+}
+
+func TestLongLineWarning(t *testing.T) {
+	ctx, err := WithWarnings(WithMaxLineSize(context.TODO(), 8), []string{RuleMaxLineBytes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := []byte("a\nbbbbbbbbbbbbbbbb\nc\n")
+
+	errs := LintReaderWithDefinition(ctx, &editorconfig.Definition{}, "long.txt", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %v", errs)
+	}
+
+	var ve ValidationError
+	if ok := errors.As(errs[0], &ve); !ok || ve.Rule != RuleMaxLineBytes || ve.Severity != SeverityWarning {
+		t.Errorf("a %s warning was expected, got %v", RuleMaxLineBytes, errs[0])
+	}
+}
+
+func TestInsertFinalNewlineBOM(t *testing.T) {
+	yes := true
+
+	def := &editorconfig.Definition{
+		Charset:            "utf-8-bom",
+		InsertFinalNewline: &yes,
+	}
+
+	// The BOM isn't part of the decoded content.
+	file := []byte("\xef\xbb\xbfA file\nwithout a final newline")
+
+	errs := LintReaderWithDefinition(context.TODO(), def, "bom.txt", bytes.NewReader(file), int64(len(file)))
+	if len(errs) != 1 {
+		t.Fatalf("one error was expected, got %v", errs)
+	}
+
+	var ve ValidationError
+	if ok := errors.As(errs[0], &ve); !ok || ve.Rule != RuleFinalNewline || ve.Index != 1 {
+		t.Errorf("a final newline error was expected on the second line, got %s", errs[0])
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	// The invalid sequences at the start of a file make it look binary.
+	prefix := strings.Repeat("hello world\n", 50)
+
+	tests := []struct {
+		Name    string
+		Charset string
+		Errors  int
+	}{
+		{
+			Name:    "utf-8",
+			Charset: "utf-8",
+			Errors:  1,
+		}, {
+			Name:    "utf-8-bom",
+			Charset: "utf-8-bom",
+			Errors:  1,
+		}, {
+			Name:    "latin1",
+			Charset: "latin1",
+		}, {
+			Name: "unset",
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			file := []byte(prefix + "caf\xc3\n")
+			if tc.Charset == "utf-8-bom" {
+				file = append([]byte{0xef, 0xbb, 0xbf}, file...)
+			}
+
+			def := &editorconfig.Definition{Charset: tc.Charset}
+
+			errs := LintReaderWithDefinition(ctx, def, "a.txt", bytes.NewReader(file), int64(len(file)))
+			if len(errs) != tc.Errors {
+				t.Fatalf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+
+			for _, err := range errs {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); !ok || ve.Rule != RuleCharset || ve.Index != 50 || ve.Position != 3 {
+					t.Errorf("a charset error was expected on the last line, got %s", err)
+				}
+			}
+		})
+	}
+}
+
+func TestASCII(t *testing.T) {
+	prefix := strings.Repeat("hello world\n", 50)
+
+	tests := []struct {
+		Name     string
+		File     []byte
+		Errors   int
+		Position int
+	}{
+		{
+			Name: "ascii",
+			File: []byte(prefix + "cafe\n"),
+		}, {
+			Name:     "accented character",
+			File:     []byte(prefix + "caf\xc3\xa9\n"),
+			Errors:   1,
+			Position: 3,
+		}, {
+			Name:     "emoji",
+			File:     []byte(prefix + "smile \xf0\x9f\x98\x80\n"),
+			Errors:   1,
+			Position: 6,
+		}, {
+			Name:   "byte order mark",
+			File:   []byte("\xef\xbb\xbfhello\n"),
+			Errors: 1,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def := &editorconfig.Definition{Charset: ASCII}
+
+			errs := LintReaderWithDefinition(ctx, def, "a.txt", bytes.NewReader(tc.File), int64(len(tc.File)))
+			if len(errs) != tc.Errors {
+				t.Fatalf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+
+			for _, err := range errs {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); !ok || ve.Rule != RuleCharset {
+					t.Fatalf("a charset error was expected, got %s", err)
+				}
+
+				if ve.Line != nil && (ve.Index != 50 || ve.Position != tc.Position) {
+					t.Errorf("an error at 51:%d was expected, got %s", tc.Position+1, ve)
+				}
+			}
+		})
+	}
+}
+
+func TestLatin1(t *testing.T) {
+	prefix := strings.Repeat("hello world\n", 50)
+
+	tests := []struct {
+		Name   string
+		File   []byte
+		Errors int
+	}{
+		{
+			Name: "latin1",
+			File: []byte(prefix + "caf\xe9\n"),
+		}, {
+			Name:   "utf-8",
+			File:   []byte(prefix + "caf\xc3\xa9\n"),
+			Errors: 1,
+		}, {
+			Name:   "byte order mark",
+			File:   []byte("\xef\xbb\xbfhello\n"),
+			Errors: 1,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def := &editorconfig.Definition{Charset: Latin1}
+
+			errs := LintReaderWithDefinition(ctx, def, "a.txt", bytes.NewReader(tc.File), int64(len(tc.File)))
+			if len(errs) != tc.Errors {
+				t.Fatalf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+
+			for _, err := range errs {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); !ok || ve.Rule != RuleCharset {
+					t.Errorf("a charset error was expected, got %s", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxConsecutiveBlankLines(t *testing.T) {
+	tests := []struct {
+		Name          string
+		MaxBlankLines string
+		File          []byte
+		Indexes       []int
+	}{
+		{
+			Name:          "within limit",
+			MaxBlankLines: "1",
+			File:          []byte("a\n\nb\n\nc\n"),
+		}, {
+			Name:          "too many",
+			MaxBlankLines: "1",
+			File:          []byte("a\n\n\n\nb\n"),
+			Indexes:       []int{2},
+		}, {
+			Name:          "whitespace only lines",
+			MaxBlankLines: "1",
+			File:          []byte("a\n  \n\t\r\nb\r\n"),
+			Indexes:       []int{2},
+		}, {
+			Name:          "none allowed",
+			MaxBlankLines: "0",
+			File:          []byte("a\n\nb\n\nc\n"),
+			Indexes:       []int{1, 3},
+		}, {
+			Name:          "run ending at eof",
+			MaxBlankLines: "2",
+			File:          []byte("a\n\n\n\n"),
+			Indexes:       []int{3},
+		}, {
+			Name:          "off",
+			MaxBlankLines: "off",
+			File:          []byte("a\n\n\n\nb\n"),
+		}, {
+			Name:          "unset",
+			MaxBlankLines: "unset",
+			File:          []byte("a\n\n\n\nb\n"),
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				Raw: map[string]string{
+					"max_consecutive_blank_lines": tc.MaxBlankLines,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if len(errs) != len(tc.Indexes) {
+				t.Fatalf("%d errors were expected, got %d", len(tc.Indexes), len(errs))
+			}
+
+			for i, err := range errs {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); !ok {
+					t.Fatalf("a validation error was expected, got %s", err)
+				}
+
+				if ve.Rule != RuleBlankLines {
+					t.Errorf("rule %q was expected, got %q", RuleBlankLines, ve.Rule)
+				}
+
+				if ve.Index != tc.Indexes[i] {
+					t.Errorf("error expected on line %d, got %d", tc.Indexes[i], ve.Index)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxConsecutiveBlankLinesWithTrailingWhitespace(t *testing.T) {
+	trim := true
+
+	def, err := newDefinition(&editorconfig.Definition{
+		TrimTrailingWhitespace: &trim,
+		Raw: map[string]string{
+			"max_consecutive_blank_lines": "1",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := []byte("a\n\n  \n\nb\n")
+
+	errs := validate(context.TODO(), bytes.NewReader(file), int64(len(file)), "utf-8", def)
+
+	expected := []struct {
+		Rule  string
+		Index int
+	}{
+		{Rule: RuleTrailingWhitespace, Index: 2},
+		{Rule: RuleBlankLines, Index: 2},
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("%d errors were expected, got %v", len(expected), errs)
+	}
+
+	for i, err := range errs {
+		var ve ValidationError
+		if ok := errors.As(err, &ve); !ok {
+			t.Fatalf("a validation error was expected, got %s", err)
+		}
+
+		if ve.Rule != expected[i].Rule || ve.Index != expected[i].Index {
+			t.Errorf("%s error expected on line %d, got %s on %d", expected[i].Rule, expected[i].Index, ve.Rule, ve.Index)
+		}
+	}
+}
+
+func TestMaxConsecutiveBlankLinesInvalid(t *testing.T) {
+	_, err := newDefinition(&editorconfig.Definition{
+		Raw: map[string]string{
+			"max_consecutive_blank_lines": "-1",
+		},
+	})
+	if !errors.Is(err, ErrConfiguration) {
+		t.Errorf("a configuration error was expected, got %v", err)
+	}
+}
+
+func TestNewDefinitionIndentSizeInvalid(t *testing.T) {
+	_, err := newDefinition(&editorconfig.Definition{
+		IndentSize: "four",
+	})
+	if !errors.Is(err, ErrConfiguration) {
+		t.Errorf("a configuration error was expected, got %v", err)
+	}
+}
+
+func TestNewDefinitionTabWidthInvalid(t *testing.T) {
+	for _, tw := range []string{"0", "-2", "four"} {
+		_, err := newDefinition(&editorconfig.Definition{
+			Raw: map[string]string{
+				"tab_width": tw,
+			},
+		})
+		if !errors.Is(err, ErrConfiguration) {
+			t.Errorf("a configuration error was expected for %q, got %v", tw, err)
+		}
+	}
+}
+
+func TestHasRedundantTabWidth(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Raw       map[string]string
+		Redundant bool
+	}{
+		{
+			Name:      "space without tab_width",
+			Raw:       map[string]string{"indent_style": "space", "indent_size": "2"},
+			Redundant: false,
+		}, {
+			Name:      "space with tab_width",
+			Raw:       map[string]string{"indent_style": "space", "indent_size": "2", "tab_width": "4"},
+			Redundant: true,
+		}, {
+			Name:      "tab with tab_width",
+			Raw:       map[string]string{"indent_style": "tab", "tab_width": "4"},
+			Redundant: false,
+		}, {
+			Name:      "space with tab_width and max_line_length",
+			Raw:       map[string]string{"indent_style": "space", "tab_width": "4", "max_line_length": "80"},
+			Redundant: false,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: tc.Raw["indent_style"],
+				IndentSize:  tc.Raw["indent_size"],
+				Raw:         tc.Raw,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if def.hasRedundantTabWidth() != tc.Redundant {
+				t.Errorf("redundant %v was expected, got %v", tc.Redundant, !tc.Redundant)
+			}
+		})
+	}
+}
+
+func TestCharsetBOM(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Charset string
+		File    string
+		Errors  int
+	}{
+		{
+			Name:    "utf-8-bom with a bom",
+			Charset: "utf-8-bom",
+			File:    "\xef\xbb\xbfhello\n",
+		}, {
+			Name:    "utf-8-bom without a bom",
+			Charset: "utf-8-bom",
+			File:    "hello\n",
+			Errors:  1,
+		}, {
+			Name:    "utf-8 with a bom",
+			Charset: "utf-8",
+			File:    "\xef\xbb\xbfhello\n",
+			Errors:  1,
+		}, {
+			Name:    "utf-8 without a bom",
+			Charset: "utf-8",
+			File:    "hello\n",
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			d := &editorconfig.Definition{Charset: tc.Charset}
+			r := strings.NewReader(tc.File)
+
+			errs := LintReaderWithDefinition(ctx, d, "file.txt", r, int64(len(tc.File)))
+			if len(errs) != tc.Errors {
+				t.Errorf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+		})
+	}
+}
+
+func TestByteOrderMark(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Charset  string
+		File     string
+		Index    int
+		Position int
+	}{
+		{
+			Name:     "utf-8 bom after a merge",
+			Charset:  "utf-8",
+			File:     "hello\nwor\xef\xbb\xbfld\n",
+			Index:    1,
+			Position: 3,
+		}, {
+			Name:     "concatenated utf-8 bom files",
+			Charset:  "utf-8-bom",
+			File:     "\xef\xbb\xbfhello\n\xef\xbb\xbfworld\n",
+			Index:    1,
+			Position: 0,
+		}, {
+			Name:     "concatenated utf-16le files",
+			Charset:  "utf-16le",
+			File:     "\xff\xfeh\x00\n\x00\xff\xfew\x00\n\x00",
+			Index:    1,
+			Position: 0,
+		}, {
+			// The charset is probed on the first 512 bytes only.
+			Name:     "utf-16le bom within a utf-8 file",
+			Charset:  "utf-8",
+			File:     strings.Repeat("hello\n", 100) + "wor\xff\xfeld\n",
+			Index:    100,
+			Position: 3,
+		}, {
+			Name:     "utf-16be bom within a latin1 file",
+			Charset:  "latin1",
+			File:     "hello\n\xfe\xffworld\n",
+			Index:    1,
+			Position: 0,
+		}, {
+			Name:     "leading bom only",
+			Charset:  "utf-8-bom",
+			File:     "\xef\xbb\xbfhello\nworld\n",
+			Index:    -1,
+			Position: -1,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			d := &editorconfig.Definition{Charset: tc.Charset}
+			r := strings.NewReader(tc.File)
+
+			errs := LintReaderWithDefinition(ctx, d, "file.txt", r, int64(len(tc.File)))
+			if tc.Index < 0 {
+				if len(errs) > 0 {
+					t.Errorf("no errors were expected, got %v", errs)
+				}
+
+				return
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("one error was expected, got %v", errs)
+			}
+
+			var ve ValidationError
+			if ok := errors.As(errs[0], &ve); !ok || ve.Rule != RuleByteOrderMark {
+				t.Fatalf("a byte order mark error was expected, got %v", errs[0])
+			}
+
+			if ve.Index != tc.Index || ve.Position != tc.Position {
+				t.Errorf("error at %d:%d was expected, got %d:%d", tc.Index, tc.Position, ve.Index, ve.Position)
+			}
+		})
+	}
+}
+
+func TestTrimTrailingBlankLines(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Value string
+		File  []byte
+		Index int
+	}{
+		{
+			Name:  "no blank lines",
+			Value: "true",
+			File:  []byte("a\n\nb\n"),
+			Index: -1,
+		}, {
+			Name:  "one blank line",
+			Value: "true",
+			File:  []byte("a\nb\n\n"),
+			Index: 2,
+		}, {
+			Name:  "many blank lines",
+			Value: "true",
+			File:  []byte("a\n\nb\n\n \t\n\r\n"),
+			Index: 3,
+		}, {
+			Name:  "without final newline",
+			Value: "true",
+			File:  []byte("a\n\n  "),
+			Index: 1,
+		}, {
+			Name:  "false",
+			Value: "false",
+			File:  []byte("a\n\n\n"),
+			Index: -1,
+		}, {
+			Name:  "unset",
+			Value: "unset",
+			File:  []byte("a\n\n\n"),
+			Index: -1,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				Raw: map[string]string{
+					"trim_trailing_blank_lines": tc.Value,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if tc.Index < 0 {
+				if len(errs) != 0 {
+					t.Fatalf("no errors were expected, got %v", errs)
+				}
+
+				return
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("one error was expected, got %v", errs)
+			}
+
+			var ve ValidationError
+			if ok := errors.As(errs[0], &ve); !ok {
+				t.Fatalf("a validation error was expected, got %s", errs[0])
+			}
+
+			if ve.Rule != RuleTrailingBlankLines || ve.Index != tc.Index {
+				t.Errorf("%s error expected on line %d, got %s on %d", RuleTrailingBlankLines, tc.Index, ve.Rule, ve.Index)
+			}
+		})
+	}
+}
+
+func TestTrimTrailingBlankLinesWithTrailingWhitespace(t *testing.T) {
+	trim := true
+
+	def, err := newDefinition(&editorconfig.Definition{
+		TrimTrailingWhitespace: &trim,
+		Raw: map[string]string{
+			"trim_trailing_blank_lines": "true",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := []byte("foo\n\n  \n")
+
+	errs := validate(context.TODO(), bytes.NewReader(file), int64(len(file)), "utf-8", def)
+
+	expected := []struct {
+		Rule  string
+		Index int
+	}{
+		{Rule: RuleTrailingBlankLines, Index: 1},
+		{Rule: RuleTrailingWhitespace, Index: 2},
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("%d errors were expected, got %v", len(expected), errs)
+	}
+
+	for i, err := range errs {
+		var ve ValidationError
+		if ok := errors.As(err, &ve); !ok {
+			t.Fatalf("a validation error was expected, got %s", err)
+		}
+
+		if ve.Rule != expected[i].Rule || ve.Index != expected[i].Index {
+			t.Errorf("%s error expected on line %d, got %s on %d", expected[i].Rule, expected[i].Index, ve.Rule, ve.Index)
+		}
+	}
+}
+
+func TestSuppressionDirectives(t *testing.T) {
+	tests := []struct {
+		Name  string
+		File  string
+		Rules []string
+	}{
+		{
+			Name:  "no directives",
+			File:  "short\nhttps://example.org/long\n",
+			Rules: []string{RuleMaxLineLength},
+		}, {
+			Name: "disable max-line-length on the line",
+			File: "short\nhttps://example.org/long // eclint-disable-line max-line-length\n",
+		}, {
+			Name:  "disable another rule on the line",
+			File:  "short\nhttps://example.org/long // eclint-disable-line trailing-whitespace\n",
+			Rules: []string{RuleMaxLineLength},
+		}, {
+			Name:  "disable max-line-length but not the trailing whitespace",
+			File:  "https://example.org/long # eclint-disable-line max-line-length \n",
+			Rules: []string{RuleTrailingWhitespace},
+		}, {
+			Name: "disable all the rules on the line",
+			File: "https://example.org/long # eclint-disable-line \n",
+		}, {
+			Name:  "disable, enable",
+			File:  "# eclint-disable max-line-length\nhttps://example.org/long\n# eclint-enable\nhttps://example.org/long\n",
+			Rules: []string{RuleMaxLineLength},
+		}, {
+			Name: "disable without enable",
+			File: "# eclint-disable\nhttps://example.org/long\nhttps://example.org/long \n",
+		},
+	}
+
+	ctx := context.TODO()
+	trimTrailingWhitespace := true
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				TrimTrailingWhitespace: &trimTrailingWhitespace,
+				Raw: map[string]string{
+					"max_line_length": "16",
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := strings.NewReader(tc.File)
+
+			rules := []string{}
+
+			for _, err := range validate(ctx, r, int64(len(tc.File)), "utf-8", def) {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); !ok {
+					t.Fatalf("a validation error was expected, got %s", err)
+				}
+
+				rules = append(rules, ve.Rule)
+			}
+
+			if len(rules) != len(tc.Rules) {
+				t.Fatalf("rules %v were expected, got %v", tc.Rules, rules)
+			}
+
+			for i, rule := range tc.Rules {
+				if rules[i] != rule {
+					t.Errorf("rules %v were expected, got %v", tc.Rules, rules)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationErrorRule(t *testing.T) {
+	insertFinalNewline := true
+	trimTrailingWhitespace := true
+
+	tests := []struct {
+		Name       string
+		Definition *editorconfig.Definition
+		File       string
+		Rule       string
+	}{
+		{
+			Name:       "charset",
+			Definition: &editorconfig.Definition{Charset: "utf-8-bom"},
+			File:       "hello\n",
+			Rule:       RuleCharset,
+		}, {
+			Name:       "end of line",
+			Definition: &editorconfig.Definition{EndOfLine: editorconfig.EndOfLineLf},
+			File:       "hello\r\nworld\n",
+			Rule:       RuleEndOfLine,
+		}, {
+			Name:       "indent style",
+			Definition: &editorconfig.Definition{IndentStyle: editorconfig.IndentStyleTab},
+			File:       " hello\n",
+			Rule:       RuleIndentStyle,
+		}, {
+			Name: "indent size",
+			Definition: &editorconfig.Definition{
+				IndentStyle: editorconfig.IndentStyleSpaces,
+				IndentSize:  "4",
+			},
+			File: "  hello\n",
+			Rule: RuleIndentSize,
+		}, {
+			Name:       "final newline",
+			Definition: &editorconfig.Definition{InsertFinalNewline: &insertFinalNewline},
+			File:       "hello",
+			Rule:       RuleFinalNewline,
+		}, {
+			Name:       "trailing whitespace",
+			Definition: &editorconfig.Definition{TrimTrailingWhitespace: &trimTrailingWhitespace},
+			File:       "hello \n",
+			Rule:       RuleTrailingWhitespace,
+		}, {
+			Name: "max line length",
+			Definition: &editorconfig.Definition{
+				Raw: map[string]string{"max_line_length": "3"},
+			},
+			File: "hello\n",
+			Rule: RuleMaxLineLength,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			r := strings.NewReader(tc.File)
+
+			errs := LintReaderWithDefinition(ctx, tc.Definition, "file.txt", r, int64(len(tc.File)))
+			if len(errs) != 1 {
+				t.Fatalf("one error was expected, got %v", errs)
+			}
+
+			var ve ValidationError
+			if ok := errors.As(errs[0], &ve); !ok {
+				t.Fatalf("a validation error was expected, got %s", errs[0])
+			}
+
+			if ve.Rule != tc.Rule {
+				t.Errorf("rule %q was expected, got %q", tc.Rule, ve.Rule)
+			}
+		})
+	}
+}
+
 func TestBlockComment(t *testing.T) {
 	tests := []struct {
-		Name              string
-		BlockCommentStart string
-		BlockComment      string
-		BlockCommentEnd   string
-		File              []byte
+		Name              string
+		BlockCommentStart string
+		BlockComment      string
+		BlockCommentEnd   string
+		File              []byte
+	}{
+		{
+			Name:              "Java",
+			BlockCommentStart: "/*",
+			BlockComment:      "*",
+			BlockCommentEnd:   "*/",
+			File: []byte(`
+	/**
+	 *
+	 */
+	public class ... {}
+`),
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		// Test the nominal case
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def := &editorconfig.Definition{
+				EndOfLine:   "lf",
+				Charset:     "utf-8",
+				IndentStyle: "tab",
+			}
+			def.Raw = make(map[string]string)
+			def.Raw["block_comment_start"] = tc.BlockCommentStart
+			def.Raw["block_comment"] = tc.BlockComment
+			def.Raw["block_comment_end"] = tc.BlockCommentEnd
+			d, err := newDefinition(def)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+			for _, err := range validate(ctx, r, -1, "utf-8", d) {
+				if err != nil {
+					t.Errorf("no errors where expected, got %s", err)
+				}
+			}
+		})
+	}
+}
+
+func TestBlockString(t *testing.T) {
+	tests := []struct {
+		Name   string
+		File   string
+		Errors int
+	}{
+		{
+			Name: "docstring",
+			File: `def f():
+    """Do something.
+
+      Aligned continuation lines,
+   whatever their indentation.
+        """
+    return 1
+`,
+		}, {
+			Name: "docstring on one line",
+			File: `def f():
+    """Do something."""
+   return 1
+`,
+			Errors: 1,
+		}, {
+			Name: "code after a docstring",
+			File: `x = """
+  text
+"""
+def f():
+   return 1
+`,
+			Errors: 1,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: "space",
+				IndentSize:  "4",
+				Raw: map[string]string{
+					"block_string_start": `"""`,
+					"block_string_end":   `"""`,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := strings.NewReader(tc.File)
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if len(errs) != tc.Errors {
+				t.Errorf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+		})
+	}
+}
+
+func TestBlockStringInvalid(t *testing.T) {
+	_, err := newDefinition(&editorconfig.Definition{
+		Raw: map[string]string{
+			"block_string_start": `"""`,
+		},
+	})
+	if !errors.Is(err, ErrConfiguration) {
+		t.Errorf("a configuration error was expected, got %v", err)
+	}
+}
+
+func TestBlockCommentIndentation(t *testing.T) {
+	tests := []struct {
+		Name        string
+		IndentStyle string
+		IndentSize  string
+		File        []byte
+		Errors      int
+	}{
+		{
+			Name:        "aligned with tabs",
+			IndentStyle: "tab",
+			File:        []byte("\t/**\n\t * Hello\n\t */\n\tcode();\n"),
+		}, {
+			Name:        "misaligned with tabs",
+			IndentStyle: "tab",
+			File:        []byte("\t/**\n\t  * Hello\n\t */\n"),
+			Errors:      1,
+		}, {
+			Name:        "one line comment",
+			IndentStyle: "tab",
+			File:        []byte("\t/* Hello */\n code();\n"),
+			Errors:      1,
+		}, {
+			Name:        "aligned with spaces",
+			IndentStyle: "space",
+			IndentSize:  "4",
+			File:        []byte("    /*\n     * Hello\n     */\n    code();\n"),
+		}, {
+			Name:        "wrong indentation after the comment",
+			IndentStyle: "space",
+			IndentSize:  "4",
+			File:        []byte("/*\n * Hello\n */\n code();\n"),
+			Errors:      1,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: tc.IndentStyle,
+				IndentSize:  tc.IndentSize,
+				Raw: map[string]string{
+					"block_comment_start": "/*",
+					"block_comment":       "*",
+					"block_comment_end":   "*/",
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if len(errs) != tc.Errors {
+				t.Errorf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+		})
+	}
+}
+
+func TestMixedIndentation(t *testing.T) {
+	tests := []struct {
+		Name        string
+		IndentStyle string
+		File        []byte
+		Disabled    bool
+		Rule        string
+		Position    int
+	}{
+		{
+			Name:        "tab then spaces using spaces",
+			IndentStyle: "space",
+			File:        []byte("\t  code\n"),
+			Rule:        RuleMixedIndentation,
+			Position:    0,
+		}, {
+			Name:        "spaces then tab using spaces",
+			IndentStyle: "space",
+			File:        []byte("  \tcode\n"),
+			Rule:        RuleMixedIndentation,
+			Position:    2,
+		}, {
+			Name:        "tab using spaces",
+			IndentStyle: "space",
+			File:        []byte("\tcode\n"),
+			Rule:        RuleIndentStyle,
+			Position:    0,
+		}, {
+			Name:        "spaces then tab using tabs",
+			IndentStyle: "tab",
+			File:        []byte("  \tcode\n"),
+			Rule:        RuleMixedIndentation,
+			Position:    0,
+		}, {
+			Name:        "tab using tabs",
+			IndentStyle: "tab",
+			File:        []byte("\tcode\n"),
+		}, {
+			Name:        "disabled",
+			IndentStyle: "space",
+			File:        []byte("  \tcode\n"),
+			Disabled:    true,
+			Rule:        RuleIndentStyle,
+			Position:    2,
+		}, {
+			Name:        "within a block comment",
+			IndentStyle: "space",
+			File:        []byte("/*\n \t* Hello\n */\n"),
+			Rule:        RuleIndentStyle,
+			Position:    1,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var disabled []string
+			if tc.Disabled {
+				disabled = []string{RuleMixedIndentation}
+			}
+
+			ctx, err := WithRules(context.TODO(), nil, disabled)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: tc.IndentStyle,
+				IndentSize:  "2",
+				Raw: map[string]string{
+					"block_comment_start": "/*",
+					"block_comment":       "*",
+					"block_comment_end":   "*/",
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := validate(ctx, bytes.NewReader(tc.File), int64(len(tc.File)), "utf-8", def)
+			if tc.Rule == "" {
+				if len(errs) != 0 {
+					t.Fatalf("no errors were expected, got %v", errs)
+				}
+
+				return
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("one error was expected, got %v", errs)
+			}
+
+			var ve ValidationError
+			if ok := errors.As(errs[0], &ve); !ok {
+				t.Fatalf("a validation error was expected, got %s", errs[0])
+			}
+
+			if ve.Rule != tc.Rule || ve.Position != tc.Position {
+				t.Errorf("%s error expected at %d, got %s at %d", tc.Rule, tc.Position, ve.Rule, ve.Position)
+			}
+		})
+	}
+}
+
+func TestMixedComments(t *testing.T) {
+	tests := []struct {
+		Name   string
+		File   []byte
+		Errors int
 	}{
 		{
-			Name:              "Java",
-			BlockCommentStart: "/*",
-			BlockComment:      "*",
-			BlockCommentEnd:   "*/",
-			File: []byte(`
-	/**
-	 *
-	 */
-	public class ... {}
-`),
+			Name: "line comments aligned with anything",
+			File: []byte("int f() {\n    int x;  // note\n      // aligned with the note\n    return x;\n}\n"),
+		}, {
+			Name:   "misaligned code",
+			File:   []byte("int f() {\n  return 0;\n}\n"),
+			Errors: 1,
+		}, {
+			Name:   "line comment with tabs",
+			File:   []byte("int f() {\n\t// note\n}\n"),
+			Errors: 1,
+		}, {
+			Name: "second kind of block comments",
+			File: []byte("{-\n - Hello\n -}\n/*\n * Hello\n */\n"),
+		}, {
+			Name:   "prefix of the other kind",
+			File:   []byte("{-\n * Hello\n -}\n"),
+			Errors: 1,
+		}, {
+			Name:   "line comment within a block comment",
+			File:   []byte("/*\n  // Hello\n */\n"),
+			Errors: 1,
 		},
 	}
 
@@ -106,34 +1728,190 @@ func TestBlockComment(t *testing.T) {
 	for _, tc := range tests {
 		tc := tc
 
-		// Test the nominal case
 		t.Run(tc.Name, func(t *testing.T) {
 			t.Parallel()
 
-			def := &editorconfig.Definition{
-				EndOfLine:   "lf",
-				Charset:     "utf-8",
-				IndentStyle: "tab",
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: "space",
+				IndentSize:  "4",
+				Raw: map[string]string{
+					"line_comment":        "//, #",
+					"block_comment_start": "/*, {-",
+					"block_comment":       "*, -",
+					"block_comment_end":   "*/, -}",
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
 			}
-			def.Raw = make(map[string]string)
-			def.Raw["block_comment_start"] = tc.BlockCommentStart
-			def.Raw["block_comment"] = tc.BlockComment
-			def.Raw["block_comment_end"] = tc.BlockCommentEnd
-			d, err := newDefinition(def)
+
+			r := bytes.NewReader(tc.File)
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if len(errs) != tc.Errors {
+				t.Errorf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+		})
+	}
+}
+
+func TestHalfIndent(t *testing.T) {
+	file := []byte("switch (x) {\n  case 1:\n    f();\n  default:\n    g();\n}\n")
+
+	tests := []struct {
+		Name   string
+		Raw    map[string]string
+		File   []byte
+		Errors int
+	}{
+		{
+			Name:   "not allowed",
+			File:   file,
+			Errors: 2,
+		}, {
+			Name: "allowed",
+			Raw:  map[string]string{"indent_size_half_allowed": "true"},
+			File: file,
+		}, {
+			Name:   "other prefix",
+			Raw:    map[string]string{"indent_size_half_allowed": "true", "indent_size_half_prefix": "when"},
+			File:   []byte("match {\n  when 1:\n    f();\n  case 2:\n}\n"),
+			Errors: 1,
+		}, {
+			Name:   "only the half",
+			Raw:    map[string]string{"indent_size_half_allowed": "true"},
+			File:   []byte("switch (x) {\n   case 1:\n  f();\n}\n"),
+			Errors: 2,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: "space",
+				IndentSize:  "4",
+				Raw:         tc.Raw,
+			})
 			if err != nil {
 				t.Fatal(err)
 			}
 
 			r := bytes.NewReader(tc.File)
-			for _, err := range validate(ctx, r, -1, "utf-8", d) {
-				if err != nil {
-					t.Errorf("no errors where expected, got %s", err)
-				}
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if len(errs) != tc.Errors {
+				t.Errorf("%d errors were expected, got %v", tc.Errors, errs)
+			}
+		})
+	}
+}
+
+func TestHalfIndentInvalid(t *testing.T) {
+	for _, size := range []string{"3", ""} {
+		_, err := newDefinition(&editorconfig.Definition{
+			IndentStyle: "space",
+			IndentSize:  size,
+			Raw:         map[string]string{"indent_size_half_allowed": "true"},
+		})
+		if !errors.Is(err, ErrConfiguration) {
+			t.Errorf("indent_size %q: a configuration error was expected, got %v", size, err)
+		}
+	}
+
+	_, err := newDefinition(&editorconfig.Definition{
+		Raw: map[string]string{"indent_size_half_allowed": "yes"},
+	})
+	if !errors.Is(err, ErrConfiguration) {
+		t.Errorf("a configuration error was expected, got %v", err)
+	}
+}
+
+func TestMixedCommentsInvalid(t *testing.T) {
+	tests := []struct {
+		Name string
+		Raw  map[string]string
+	}{
+		{
+			Name: "missing end",
+			Raw: map[string]string{
+				"block_comment_start": "/*, {-",
+				"block_comment_end":   "*/",
+			},
+		}, {
+			Name: "extra prefix",
+			Raw: map[string]string{
+				"block_comment_start": "/*",
+				"block_comment":       "*, -",
+				"block_comment_end":   "*/",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: "space",
+				Raw:         tc.Raw,
+			})
+			if !errors.Is(err, ErrConfiguration) {
+				t.Errorf("a configuration error was expected, got %v", err)
 			}
 		})
 	}
 }
 
+func TestBlockCommentTrailingWhitespace(t *testing.T) {
+	ctx := context.TODO()
+
+	trim := true
+
+	def, err := newDefinition(&editorconfig.Definition{
+		IndentStyle:            "tab",
+		TrimTrailingWhitespace: &trim,
+		Raw: map[string]string{
+			"block_comment_start": "/*",
+			"block_comment":       "*",
+			"block_comment_end":   "*/",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := []byte("/** \n * Hello\t\n */ \n\tcode();\n")
+	r := bytes.NewReader(file)
+
+	errs := validate(ctx, r, int64(len(file)), "utf-8", def)
+	if len(errs) != 3 {
+		t.Fatalf("three errors were expected, got %v", errs)
+	}
+
+	for i, err := range errs {
+		var ve ValidationError
+		if ok := errors.As(err, &ve); !ok {
+			t.Fatalf("a validation error was expected, got %s", err)
+		}
+
+		if ve.Rule != RuleTrailingWhitespace || ve.Index != i {
+			t.Errorf("a trailing whitespace error was expected on line %d, got %s", i+1, ve)
+		}
+	}
+
+	if def.InsideBlockComment {
+		t.Error("the block comment should have ended despite the trailing whitespace")
+	}
+}
+
 func TestBlockCommentFailure(t *testing.T) {
 	tests := []struct {
 		Name              string
@@ -173,3 +1951,268 @@ func TestBlockCommentFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestForbidTabs(t *testing.T) {
+	tests := []struct {
+		Name        string
+		IndentStyle string
+		Value       string
+		File        []byte
+		Positions   []int
+	}{
+		{
+			Name:        "alignment tab",
+			IndentStyle: "space",
+			Value:       "true",
+			File:        []byte("code\tcomment\n"),
+			Positions:   []int{4},
+		}, {
+			Name:        "many tabs",
+			IndentStyle: "space",
+			Value:       "true",
+			File:        []byte("a\tb\tc\n"),
+			Positions:   []int{1, 3},
+		}, {
+			Name:        "no tabs",
+			IndentStyle: "space",
+			Value:       "true",
+			File:        []byte("code  comment\n"),
+		}, {
+			Name:        "false",
+			IndentStyle: "space",
+			Value:       "false",
+			File:        []byte("code\tcomment\n"),
+		}, {
+			Name:        "indenting with tabs",
+			IndentStyle: "tab",
+			Value:       "true",
+			File:        []byte("code\tcomment\n"),
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: tc.IndentStyle,
+				Raw: map[string]string{
+					"forbid_tabs": tc.Value,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(tc.File)
+
+			errs := validate(ctx, r, int64(len(tc.File)), "utf-8", def)
+			if len(errs) != len(tc.Positions) {
+				t.Fatalf("%d errors were expected, got %v", len(tc.Positions), errs)
+			}
+
+			for i, position := range tc.Positions {
+				var ve ValidationError
+				if ok := errors.As(errs[i], &ve); !ok {
+					t.Fatalf("a validation error was expected, got %s", errs[i])
+				}
+
+				if ve.Rule != RuleTabCharacter || ve.Index != 0 || ve.Position != position {
+					t.Errorf("%s error expected at 0:%d, got %s at %d:%d", RuleTabCharacter, position, ve.Rule, ve.Index, ve.Position)
+				}
+			}
+		})
+	}
+}
+
+func TestForbidTabsIndentation(t *testing.T) {
+	type expected struct {
+		Rule     string
+		Position int
+	}
+
+	tests := []struct {
+		Name     string
+		File     []byte
+		Disabled []string
+		Errors   []expected
+	}{
+		{
+			Name: "indenting tabs",
+			File: []byte("\t\tcode\tcomment\n"),
+			Errors: []expected{
+				{Rule: RuleIndentStyle, Position: 0},
+				{Rule: RuleTabCharacter, Position: 6},
+			},
+		}, {
+			Name: "mixed indentation",
+			File: []byte("  \tcode\n"),
+			Errors: []expected{
+				{Rule: RuleMixedIndentation, Position: 2},
+			},
+		}, {
+			Name:     "indentation rules disabled",
+			File:     []byte("\tcode\n"),
+			Disabled: []string{RuleIndentStyle, RuleMixedIndentation},
+			Errors: []expected{
+				{Rule: RuleTabCharacter, Position: 0},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, err := WithRules(context.TODO(), nil, tc.Disabled)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			def, err := newDefinition(&editorconfig.Definition{
+				IndentStyle: "space",
+				IndentSize:  "2",
+				Raw: map[string]string{
+					"forbid_tabs": "true",
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := validate(ctx, bytes.NewReader(tc.File), int64(len(tc.File)), "utf-8", def)
+			if len(errs) != len(tc.Errors) {
+				t.Fatalf("%d errors were expected, got %v", len(tc.Errors), errs)
+			}
+
+			for i, e := range tc.Errors {
+				var ve ValidationError
+				if ok := errors.As(errs[i], &ve); !ok {
+					t.Fatalf("a validation error was expected, got %s", errs[i])
+				}
+
+				if ve.Rule != e.Rule || ve.Position != e.Position {
+					t.Errorf("%s error expected at %d, got %s at %d", e.Rule, e.Position, ve.Rule, ve.Position)
+				}
+			}
+		})
+	}
+}
+
+func TestForbidTabsDisabled(t *testing.T) {
+	ctx, err := WithRules(context.TODO(), nil, []string{RuleTabCharacter})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := newDefinition(&editorconfig.Definition{
+		IndentStyle: "space",
+		Raw: map[string]string{
+			"forbid_tabs": "true",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := []byte("code\tcomment\n")
+
+	if errs := validate(ctx, bytes.NewReader(file), int64(len(file)), "utf-8", def); len(errs) != 0 {
+		t.Errorf("no errors were expected, got %v", errs)
+	}
+}
+
+func TestForbidTabsInvalid(t *testing.T) {
+	_, err := newDefinition(&editorconfig.Definition{
+		Raw: map[string]string{
+			"forbid_tabs": "maybe",
+		},
+	})
+	if !errors.Is(err, ErrConfiguration) {
+		t.Errorf("a configuration error was expected, got %v", err)
+	}
+}
+
+func TestOffset(t *testing.T) {
+	trim := true
+	def := &editorconfig.Definition{
+		IndentStyle:            SpaceValue,
+		TrimTrailingWhitespace: &trim,
+		Raw: map[string]string{
+			"forbid_tabs":               "true",
+			"trim_trailing_blank_lines": "true",
+		},
+	}
+
+	tests := []struct {
+		Name     string
+		File     []byte
+		Expected map[string]int
+	}{
+		{
+			Name: "lines",
+			File: []byte("abc\nde \r\nf\tg\nhi\n\n\n"),
+			Expected: map[string]int{
+				RuleTrailingWhitespace: 6,
+				RuleTabCharacter:       10,
+				RuleTrailingBlankLines: 16,
+			},
+		}, {
+			Name: "utf-8 bom",
+			File: []byte("\xef\xbb\xbfab\ncd \n"),
+			Expected: map[string]int{
+				RuleTrailingWhitespace: 8,
+			},
+		}, {
+			Name: "invalid utf-8",
+			File: []byte(strings.Repeat("a", 600) + "\n\xff \n"),
+			Expected: map[string]int{
+				RuleTrailingWhitespace: 602,
+			},
+		}, {
+			Name: "utf-16le",
+			File: []byte("\xff\xfea\x00\x00\x4e\n\x00c\x00d\x00 \x00\n\x00"),
+			Expected: map[string]int{
+				RuleTrailingWhitespace: 12,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			file := tc.File
+
+			errs := LintReaderWithDefinition(context.TODO(), def, "a.txt", bytes.NewReader(file), int64(len(file)))
+
+			if len(errs) != len(tc.Expected) {
+				t.Fatalf("%d errors were expected, got %v", len(tc.Expected), errs)
+			}
+
+			for _, err := range errs {
+				var ve ValidationError
+				if ok := errors.As(err, &ve); !ok {
+					t.Fatalf("a validation error was expected, got %s", err)
+				}
+
+				if ve.Offset != tc.Expected[ve.Rule] {
+					t.Errorf("%s: offset %d was expected, got %d", ve.Rule, tc.Expected[ve.Rule], ve.Offset)
+				}
+
+				// The offset points at the same byte as the line and position.
+				if ve.Position < len(ve.Line) && file[ve.Offset] != ve.Line[ve.Position] {
+					t.Errorf("%s: %q was expected at offset %d, got %q", ve.Rule, ve.Line[ve.Position], ve.Offset, file[ve.Offset])
+				}
+			}
+		})
+	}
+}
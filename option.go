@@ -2,18 +2,55 @@ package eclint
 
 import (
 	"io"
+	"sync"
 )
 
 // Option contains the environment of the program.
 //
 // When ShowErrorQuantity is 0, it will show all the errors. Use ShowAllErrors false to disable this.
+// Quiet only shows the number of errors of each file having some.
+// When Changes is set, only the errors found on the changed lines are kept.
+// Compact drops the blank line following the errors of each file.
+// FailOnWarning counts the warnings along with the errors to fail the run.
+// PathStyle is how the filenames are printed, see PathStyleRelative and PathStyleAbsolute.
+// ShowRuleNames appends the rule, e.g. [trailing-whitespace], to the messages of the text format.
+// Stdout receives the output, see NewSyncWriter when it's shared by several goroutines.
 type Option struct {
 	IsTerminal        bool
 	NoColors          bool
 	ShowAllErrors     bool
 	Summary           bool
+	Quiet             bool
+	Compact           bool
 	FixAllErrors      bool
+	DryRun            bool
+	FailOnWarning     bool
+	ShowRuleNames     bool
 	ShowErrorQuantity int
-	Exclude           string
+	Jobs              int
+	Exclude           []string
+	Changes           ChangedLines
+	Format            string
+	PathStyle         string
+	Version           string
 	Stdout            io.Writer
 }
+
+// SyncWriter serializes the writes to the underlying writer, each write
+// being kept whole, e.g. a block of colored output.
+type SyncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewSyncWriter wraps the writer so it can be shared by several goroutines.
+func NewSyncWriter(w io.Writer) *SyncWriter {
+	return &SyncWriter{w: w}
+}
+
+func (s *SyncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Write(p) //nolint:wrapcheck
+}
@@ -5,20 +5,202 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/go-logr/logr"
 	"github.com/logrusorgru/aurora"
 )
 
+const (
+	// FormatText is the rich output format meant for humans.
+	FormatText = "text"
+	// FormatJSON is the machine-readable output format.
+	FormatJSON = "json"
+	// FormatSarif is the Static Analysis Results Interchange Format (SARIF) 2.1.0.
+	FormatSarif = "sarif"
+	// FormatGitHub is the GitHub Actions workflow commands format.
+	FormatGitHub = "github"
+	// FormatCheckstyle is the Checkstyle XML format, e.g. for Jenkins.
+	FormatCheckstyle = "checkstyle"
+	// FormatGitLab is the GitLab Code Quality report format.
+	FormatGitLab = "gitlab"
+	// FormatJUnit is the JUnit XML format, one test case per file.
+	FormatJUnit = "junit"
+	// FormatNull prints nothing, only the exit status matters.
+	FormatNull = "null"
+)
+
+const (
+	// PathStyleRelative prints the filenames relative to the working directory.
+	PathStyleRelative = "relative"
+	// PathStyleAbsolute prints the absolute filenames.
+	PathStyleAbsolute = "absolute"
+)
+
+// ErrUnknownFormat represents an unsupported output format.
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// ErrUnknownPathStyle represents an unsupported style of filenames.
+var ErrUnknownPathStyle = errors.New("unknown path style")
+
+// Printer outputs the errors found during a run.
+type Printer interface {
+	// Print receives the errors of one file.
+	Print(ctx context.Context, filename string, errs []error) error
+	// Flush writes what was kept until the end of the run.
+	Flush(ctx context.Context) error
+}
+
+// NewPrinter builds the printer matching the format of the option.
+//
+// The filenames are printed as listed, unless the PathStyle of the option
+// is either PathStyleRelative or PathStyleAbsolute.
+func NewPrinter(opt *Option) (Printer, error) {
+	printer, err := newFormatPrinter(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opt.PathStyle {
+	case "":
+		return printer, nil
+	case PathStyleRelative, PathStyleAbsolute:
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the working directory: %w", err)
+		}
+
+		return &pathPrinter{Printer: printer, style: opt.PathStyle, wd: wd}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPathStyle, opt.PathStyle)
+	}
+}
+
+// newFormatPrinter builds the printer of the format.
+func newFormatPrinter(opt *Option) (Printer, error) {
+	switch opt.Format {
+	case "", FormatText:
+		return &textPrinter{opt: opt}, nil
+	case FormatJSON:
+		return newJSONPrinter(opt), nil
+	case FormatSarif:
+		return newSarifPrinter(opt), nil
+	case FormatGitHub:
+		return &githubPrinter{opt: opt}, nil
+	case FormatCheckstyle:
+		return newCheckstylePrinter(opt), nil
+	case FormatGitLab:
+		return newGitLabPrinter(opt), nil
+	case FormatJUnit:
+		return newJUnitPrinter(opt), nil
+	case FormatNull:
+		return nullPrinter{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, opt.Format)
+	}
+}
+
+// textPrinter prints the errors as soon as they are received.
+type textPrinter struct {
+	opt *Option
+}
+
+func (p *textPrinter) Print(ctx context.Context, filename string, errs []error) error {
+	return PrintErrors(ctx, p.opt, filename, errs)
+}
+
+func (p *textPrinter) Flush(_ context.Context) error {
+	return nil
+}
+
+// pathPrinter rewrites the filenames before handing the errors to the printer.
+type pathPrinter struct {
+	Printer
+	style string
+	wd    string
+}
+
+func (p *pathPrinter) Print(ctx context.Context, filename string, errs []error) error {
+	name := p.path(filename)
+	rewritten := make([]error, len(errs))
+
+	for i, err := range errs {
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			ve.Filename = name
+			err = ve
+		}
+
+		rewritten[i] = err
+	}
+
+	return p.Printer.Print(ctx, name, rewritten)
+}
+
+// path resolves the filename, the relative one falling back to the absolute
+// one when it's on another volume.
+func (p *pathPrinter) path(filename string) string {
+	name := filename
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(p.wd, name)
+	}
+
+	if p.style == PathStyleAbsolute {
+		return name
+	}
+
+	if rel, err := filepath.Rel(p.wd, name); err == nil {
+		return rel
+	}
+
+	return name
+}
+
+// nullPrinter discards the errors.
+type nullPrinter struct{}
+
+func (nullPrinter) Print(_ context.Context, _ string, _ []error) error {
+	return nil
+}
+
+func (nullPrinter) Flush(_ context.Context) error {
+	return nil
+}
+
 // PrintErrors is the rich output of the program.
+//
+// The errors of the file are written at once, so the output of concurrent
+// calls sharing a SyncWriter isn't interleaved. They are sorted, see sortErrors.
 func PrintErrors(ctx context.Context, opt *Option, filename string, errs []error) error { //nolint:gocognit
 	counter := 0
 
+	errs = sortErrors(errs)
+
 	log := logr.FromContextOrDiscard(ctx)
-	stdout := opt.Stdout
+	stdout := bytes.NewBuffer(make([]byte, 0, 1024))
+
+	colors := opt.IsTerminal && !opt.NoColors
+	au := aurora.NewAurora(colors)
+
+	if opt.Quiet {
+		for _, err := range errs {
+			if err != nil {
+				counter++
+			}
+		}
 
-	au := aurora.NewAurora(opt.IsTerminal && !opt.NoColors)
+		if counter > 0 {
+			fmt.Fprintf(stdout, "%s: %d errors\n", au.Magenta(filename), counter)
+		}
+
+		return writeBlock(opt.Stdout, stdout)
+	}
 
 	for _, err := range errs {
 		if err != nil { //nolint:nestif
@@ -33,9 +215,19 @@ func PrintErrors(ctx context.Context, opt *Option, filename string, errs []error
 				if !opt.Summary {
 					vi := au.Green(strconv.Itoa(ve.Index + 1)).Bold()
 					vp := au.Green(strconv.Itoa(ve.Position + 1)).Bold()
-					fmt.Fprintf(stdout, "%s:%s: %s\n", vi, vp, ve.Message)
 
-					l, err := errorAt(au, ve.Line, ve.Position)
+					message := ve.Message
+					if opt.ShowRuleNames && ve.Rule != "" {
+						message += " " + au.Faint("["+ve.Rule+"]").String()
+					}
+
+					if Severity(ve) == SeverityWarning {
+						fmt.Fprintf(stdout, "%s:%s: %s %s\n", vi, vp, au.Yellow("warning:").Bold(), message)
+					} else {
+						fmt.Fprintf(stdout, "%s:%s: %s\n", vi, vp, message)
+					}
+
+					l, column, err := errorAt(au, ve.Line, ve.Position, Severity(ve))
 					if err != nil {
 						log.Error(err, "line formatting failure", "error", ve)
 
@@ -43,6 +235,11 @@ func PrintErrors(ctx context.Context, opt *Option, filename string, errs []error
 					}
 
 					fmt.Fprintln(stdout, l)
+
+					// Without the colors, a caret points at the error.
+					if !colors {
+						fmt.Fprintf(stdout, "%s^\n", strings.Repeat(" ", column))
+					}
 				}
 			} else {
 				log.V(2).Info("lint error", "filename", filename, "error", err.Error())
@@ -65,60 +262,164 @@ func PrintErrors(ctx context.Context, opt *Option, filename string, errs []error
 
 	if counter > 0 {
 		if !opt.Summary {
-			fmt.Fprintln(stdout, "")
+			if !opt.Compact {
+				fmt.Fprintln(stdout, "")
+			}
 		} else {
 			fmt.Fprintf(stdout, "%s: %d errors\n", au.Magenta(filename), counter)
 		}
 	}
 
+	return writeBlock(opt.Stdout, stdout)
+}
+
+// sortErrors returns a copy of the errors sorted by line, position, and rule,
+// so the output doesn't depend on the order of the validators. The errors
+// that aren't validation errors, e.g. an unreadable file, come first in their
+// original order.
+func sortErrors(errs []error) []error {
+	sorted := make([]error, len(errs))
+	copy(sorted, errs)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		var a, b ValidationError
+
+		okA := errors.As(sorted[i], &a)
+		okB := errors.As(sorted[j], &b)
+
+		switch {
+		case !okA || !okB:
+			return !okA && okB
+		case a.Index != b.Index:
+			return a.Index < b.Index
+		case a.Position != b.Position:
+			return a.Position < b.Position
+		default:
+			return a.Rule < b.Rule
+		}
+	})
+
+	return sorted
+}
+
+// writeBlock writes the buffered output with a single call.
+func writeBlock(w io.Writer, b *bytes.Buffer) error {
+	if b.Len() == 0 {
+		return nil
+	}
+
+	if _, err := w.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("cannot write the errors: %w", err)
+	}
+
 	return nil
 }
 
-// errorAt highlights the ValidationError position within the line.
-func errorAt(au aurora.Aurora, line []byte, position int) (string, error) {
-	b := bytes.NewBuffer(make([]byte, len(line)))
+// errorAt highlights the ValidationError position within the line, in red
+// or in yellow for the warnings, and returns the column of the highlight.
+//
+// Tabs are expanded to the next tab stop so the highlight matches the
+// column of the error.
+func errorAt( //nolint:cyclop
+	au aurora.Aurora,
+	line []byte,
+	position int,
+	severity string,
+) (string, int, error) {
+	b := bytes.NewBuffer(make([]byte, 0, len(line)))
+	column := 0
 
-	if position > len(line)-1 {
-		position = len(line) - 1
+	// The error may point after the line, e.g. a missing final newline.
+	if position > len(line) {
+		position = len(line)
+	}
+
+	// Rewind the 0x10xxxxxx that are UTF-8 continuation markers
+	for position > 0 && position < len(line) && (line[position]>>6) == 0b10 {
+		position--
 	}
 
 	for i := 0; i < position; i++ {
 		if line[i] != cr && line[i] != lf {
-			if err := b.WriteByte(line[i]); err != nil {
-				return "", fmt.Errorf("error writing byte: %w", err)
+			if err := writeByteAt(b, line, i, &column); err != nil {
+				return "", 0, err
 			}
 		}
 	}
 
-	// Rewind the 0x10xxxxxx that are UTF-8 continuation markers
-	for i := position; i > 0; i-- {
-		if (line[i] >> 6) != 0b10 {
-			break
-		}
-		position--
+	start := column
+
+	// The whole character is highlighted.
+	r, size := ' ', 1
+	if position >= 0 && position < len(line) {
+		r, size = utf8.DecodeRune(line[position:])
 	}
 
-	// XXX this will break every non latin1 line.
 	s := " "
-	if position < len(line)-1 {
-		s = string(line[position : position+1])
+	width := 1
+	// The line ending isn't printable, e.g. a stray cr.
+	if position >= 0 && position < len(line) && line[position] != cr && line[position] != lf {
+		s = string(line[position : position+size])
+		width = runeWidth(r)
+
+		if line[position] == tab {
+			s = strings.Repeat(" ", tabStop(column))
+			width = len(s)
+		}
+	}
+
+	column += width
+
+	highlight := au.White(s).BgRed()
+	if severity == SeverityWarning {
+		highlight = au.Black(s).BgYellow()
 	}
 
-	if _, err := b.WriteString(au.White(s).BgRed().String()); err != nil {
-		return "", fmt.Errorf("error writing string: %w", err)
+	if _, err := b.WriteString(highlight.String()); err != nil {
+		return "", 0, fmt.Errorf("error writing string: %w", err)
 	}
 
-	for i := position + 1; i < len(line); i++ {
+	for i := position + size; i < len(line); i++ {
 		if line[i] != cr && line[i] != lf {
-			if err := b.WriteByte(line[i]); err != nil {
-				return "", fmt.Errorf("error writing byte: %w", err)
+			if err := writeByteAt(b, line, i, &column); err != nil {
+				return "", 0, err
 			}
+		}
+	}
 
-			if (line[i] >> 6) == 0b10 {
-				i++
-			}
+	return b.String(), start, nil
+}
+
+// tabStop gives the number of spaces a tab at the given column spans.
+func tabStop(column int) int {
+	return DefaultTabWidth - column%DefaultTabWidth
+}
+
+// writeByteAt writes the byte of the line, expanding the tabs, and moves the
+// column by the width of the character it starts, see runeWidth.
+func writeByteAt(b *bytes.Buffer, line []byte, i int, column *int) error {
+	c := line[i]
+
+	if c == tab {
+		n := tabStop(*column)
+		*column += n
+
+		if _, err := b.WriteString(strings.Repeat(" ", n)); err != nil {
+			return fmt.Errorf("error writing string: %w", err)
 		}
+
+		return nil
+	}
+
+	// UTF-8 continuation bytes don't move the column.
+	if (c >> 6) != 0b10 {
+		r, _ := utf8.DecodeRune(line[i:])
+		*column += runeWidth(r)
 	}
 
-	return b.String(), nil
+	if err := b.WriteByte(c); err != nil {
+		return fmt.Errorf("error writing byte: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,104 @@
+package eclint
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// checkstyleVersion is the version of the Checkstyle format being mimicked.
+const checkstyleVersion = "4.3"
+
+type checkstyleLog struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+// checkstyleError uses one-based line and column, omitted when unknown.
+type checkstyleError struct {
+	Line     int    `xml:"line,attr,omitempty"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstylePrinter keeps all the files to emit a single Checkstyle XML document.
+type checkstylePrinter struct {
+	opt   *Option
+	files []checkstyleFile
+}
+
+func newCheckstylePrinter(opt *Option) *checkstylePrinter {
+	return &checkstylePrinter{
+		opt:   opt,
+		files: make([]checkstyleFile, 0),
+	}
+}
+
+func (p *checkstylePrinter) Print(_ context.Context, filename string, errs []error) error {
+	file := checkstyleFile{Name: filename}
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		e := checkstyleError{
+			Severity: Severity(err),
+			Message:  err.Error(),
+			Source:   "eclint",
+		}
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			e.Line = ve.Index + 1
+			e.Column = ve.Position + 1
+			e.Message = ve.Message
+
+			if ve.Rule != "" {
+				e.Source = "eclint." + ve.Rule
+			}
+		}
+
+		file.Errors = append(file.Errors, e)
+	}
+
+	// The files without errors are omitted.
+	if len(file.Errors) > 0 {
+		p.files = append(p.files, file)
+	}
+
+	return nil
+}
+
+func (p *checkstylePrinter) Flush(_ context.Context) error {
+	if _, err := io.WriteString(p.opt.Stdout, xml.Header); err != nil {
+		return fmt.Errorf("cannot write the XML header: %w", err)
+	}
+
+	enc := xml.NewEncoder(p.opt.Stdout)
+	enc.Indent("", "  ")
+
+	err := enc.Encode(checkstyleLog{
+		Version: checkstyleVersion,
+		Files:   p.files,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot encode the Checkstyle document: %w", err)
+	}
+
+	if _, err := io.WriteString(p.opt.Stdout, "\n"); err != nil {
+		return fmt.Errorf("cannot write the final newline: %w", err)
+	}
+
+	return nil
+}
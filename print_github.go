@@ -0,0 +1,62 @@
+package eclint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// githubDataEscaper escapes the message of a workflow command.
+var githubDataEscaper = strings.NewReplacer( //nolint:gochecknoglobals
+	"%", "%25",
+	"\r", "%0D",
+	"\n", "%0A",
+)
+
+// githubPropertyEscaper escapes the values of the workflow command properties.
+var githubPropertyEscaper = strings.NewReplacer( //nolint:gochecknoglobals
+	"%", "%25",
+	"\r", "%0D",
+	"\n", "%0A",
+	":", "%3A",
+	",", "%2C",
+)
+
+// githubPrinter prints the errors as GitHub Actions workflow commands.
+//
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions
+type githubPrinter struct {
+	opt *Option
+}
+
+func (p *githubPrinter) Print(_ context.Context, filename string, errs []error) error {
+	file := githubPropertyEscaper.Replace(filename)
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			fmt.Fprintf(
+				p.opt.Stdout,
+				"::%s file=%s,line=%d,col=%d::%s\n",
+				Severity(ve),
+				file,
+				ve.Index+1,
+				ve.Position+1,
+				githubDataEscaper.Replace(ve.Message),
+			)
+		} else {
+			fmt.Fprintf(p.opt.Stdout, "::error file=%s::%s\n", file, githubDataEscaper.Replace(err.Error()))
+		}
+	}
+
+	return nil
+}
+
+func (p *githubPrinter) Flush(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,118 @@
+package eclint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// gitlabIssue is an issue of the GitLab Code Quality report.
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+// gitlabLines uses one-based lines.
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// gitlabPrinter keeps all the issues to emit a single Code Quality report.
+type gitlabPrinter struct {
+	opt    *Option
+	issues []gitlabIssue
+}
+
+func newGitLabPrinter(opt *Option) *gitlabPrinter {
+	return &gitlabPrinter{
+		opt:    opt,
+		issues: make([]gitlabIssue, 0),
+	}
+}
+
+func (p *gitlabPrinter) Print(_ context.Context, filename string, errs []error) error {
+	path := filepath.ToSlash(filename)
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		issue := gitlabIssue{
+			Description: err.Error(),
+			CheckName:   "eclint",
+			Severity:    gitlabSeverity(err),
+			Location: gitlabLocation{
+				Path:  path,
+				Lines: gitlabLines{Begin: 1},
+			},
+		}
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			issue.Description = ve.Message
+			issue.Location.Lines.Begin = ve.Index + 1
+
+			if ve.Rule != "" {
+				issue.CheckName = ve.Rule
+			}
+		}
+
+		issue.Fingerprint = gitlabFingerprint(issue)
+
+		p.issues = append(p.issues, issue)
+	}
+
+	return nil
+}
+
+func (p *gitlabPrinter) Flush(_ context.Context) error {
+	enc := json.NewEncoder(p.opt.Stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(p.issues); err != nil {
+		return fmt.Errorf("cannot encode the Code Quality report: %w", err)
+	}
+
+	return nil
+}
+
+// gitlabFingerprint identifies the issue across runs so GitLab can compare them.
+func gitlabFingerprint(issue gitlabIssue) string {
+	h := sha256.New()
+
+	for _, s := range []string{
+		issue.Location.Path,
+		issue.CheckName,
+		strconv.Itoa(issue.Location.Lines.Begin),
+		issue.Description,
+	} {
+		// The NUL separator keeps the fields apart.
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// gitlabSeverity maps the severity of the error, the warnings being informative.
+func gitlabSeverity(err error) string {
+	if Severity(err) == SeverityWarning {
+		return "info"
+	}
+
+	return "minor"
+}
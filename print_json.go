@@ -0,0 +1,102 @@
+package eclint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// jsonError is the serialized form of an error.
+//
+// The line, column, and offset are null for the errors that aren't validation errors.
+type jsonError struct {
+	Filename string  `json:"filename"`
+	Line     *int    `json:"line"`
+	Column   *int    `json:"column"`
+	Offset   *int    `json:"offset"`
+	Message  string  `json:"message"`
+	Rule     *string `json:"rule"`
+	Severity string  `json:"severity"`
+	HelpURI  string  `json:"helpUri,omitempty"`
+}
+
+// MarshalJSON serializes the validation error using one-based line and
+// column, and the zero-based byte offset.
+func (e ValidationError) MarshalJSON() ([]byte, error) {
+	line := e.Index + 1
+	column := e.Position + 1
+	offset := e.Offset
+	rule := e.Rule
+
+	b, err := json.Marshal(jsonError{
+		Filename: e.Filename,
+		Line:     &line,
+		Column:   &column,
+		Offset:   &offset,
+		Message:  e.Message,
+		Rule:     &rule,
+		Severity: Severity(e),
+		HelpURI:  RuleHelpURI(e.Rule),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal validation error: %w", err)
+	}
+
+	return b, nil
+}
+
+// jsonPrinter keeps all the errors to emit a single JSON document.
+type jsonPrinter struct {
+	opt    *Option
+	errors []json.RawMessage
+}
+
+func newJSONPrinter(opt *Option) *jsonPrinter {
+	return &jsonPrinter{
+		opt:    opt,
+		errors: make([]json.RawMessage, 0),
+	}
+}
+
+func (p *jsonPrinter) Print(_ context.Context, filename string, errs []error) error {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var b []byte
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			ve.Filename = filename
+
+			b, err = json.Marshal(ve)
+		} else {
+			b, err = json.Marshal(jsonError{
+				Filename: filename,
+				Message:  err.Error(),
+				Severity: SeverityError,
+			})
+		}
+
+		if err != nil {
+			return fmt.Errorf("cannot marshal error: %w", err)
+		}
+
+		p.errors = append(p.errors, b)
+	}
+
+	return nil
+}
+
+func (p *jsonPrinter) Flush(_ context.Context) error {
+	enc := json.NewEncoder(p.opt.Stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(p.errors); err != nil {
+		return fmt.Errorf("cannot encode the errors: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package eclint
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitPrinter keeps all the files, as test cases, to emit a single JUnit XML test suite.
+type junitPrinter struct {
+	opt       *Option
+	start     time.Time
+	last      time.Time
+	testCases []junitTestCase
+	failures  int
+}
+
+func newJUnitPrinter(opt *Option) *junitPrinter {
+	now := time.Now()
+
+	return &junitPrinter{
+		opt:       opt,
+		start:     now,
+		last:      now,
+		testCases: make([]junitTestCase, 0),
+	}
+}
+
+func (p *junitPrinter) Print(_ context.Context, filename string, errs []error) error {
+	// The files are processed concurrently, the time is the one spent waiting for this file.
+	now := time.Now()
+	testCase := junitTestCase{
+		Name:      filename,
+		ClassName: "eclint",
+		Time:      junitSeconds(now.Sub(p.last)),
+	}
+	p.last = now
+
+	findings := make([]string, 0, len(errs))
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			findings = append(findings, fmt.Sprintf("%d:%d: %s", ve.Index+1, ve.Position+1, ve.Message))
+		} else {
+			findings = append(findings, err.Error())
+		}
+	}
+
+	if len(findings) > 0 {
+		p.failures++
+		testCase.Failure = &junitFailure{
+			Message: strings.Join(findings, "; "),
+			Type:    "eclint",
+			Text:    strings.Join(findings, "\n"),
+		}
+	}
+
+	p.testCases = append(p.testCases, testCase)
+
+	return nil
+}
+
+func (p *junitPrinter) Flush(_ context.Context) error {
+	if _, err := io.WriteString(p.opt.Stdout, xml.Header); err != nil {
+		return fmt.Errorf("cannot write the XML header: %w", err)
+	}
+
+	enc := xml.NewEncoder(p.opt.Stdout)
+	enc.Indent("", "  ")
+
+	err := enc.Encode(junitTestSuite{
+		Name:      "eclint",
+		Tests:     len(p.testCases),
+		Failures:  p.failures,
+		Time:      junitSeconds(time.Since(p.start)),
+		TestCases: p.testCases,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot encode the JUnit test suite: %w", err)
+	}
+
+	if _, err := io.WriteString(p.opt.Stdout, "\n"); err != nil {
+		return fmt.Errorf("cannot write the final newline: %w", err)
+	}
+
+	return nil
+}
+
+// junitSeconds formats the duration as seconds.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,154 @@
+package eclint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRegion uses one-based line and column.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// sarifPrinter keeps all the results to emit a single SARIF log.
+type sarifPrinter struct {
+	opt     *Option
+	rules   []sarifRule
+	seen    map[string]bool
+	results []sarifResult
+}
+
+func newSarifPrinter(opt *Option) *sarifPrinter {
+	return &sarifPrinter{
+		opt:     opt,
+		rules:   make([]sarifRule, 0),
+		seen:    make(map[string]bool),
+		results: make([]sarifResult, 0),
+	}
+}
+
+func (p *sarifPrinter) Print(_ context.Context, filename string, errs []error) error {
+	uri := filepath.ToSlash(filename)
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		result := sarifResult{
+			Level:   Severity(err),
+			Message: sarifMessage{Text: err.Error()},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: uri},
+				},
+			}},
+		}
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok {
+			result.RuleID = ve.Rule
+			result.Message.Text = ve.Message
+			result.Locations[0].PhysicalLocation.Region = &sarifRegion{
+				StartLine:   ve.Index + 1,
+				StartColumn: ve.Position + 1,
+			}
+
+			if ve.Rule != "" && !p.seen[ve.Rule] {
+				p.seen[ve.Rule] = true
+				p.rules = append(p.rules, sarifRule{ID: ve.Rule, HelpURI: RuleHelpURI(ve.Rule)})
+			}
+		}
+
+		p.results = append(p.results, result)
+	}
+
+	return nil
+}
+
+func (p *sarifPrinter) Flush(_ context.Context) error {
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:           "eclint",
+					Version:        p.opt.Version,
+					InformationURI: "https://gitlab.com/greut/eclint",
+					Rules:          p.rules,
+				},
+			},
+			Results: p.results,
+		}},
+	}
+
+	enc := json.NewEncoder(p.opt.Stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(log); err != nil {
+		return fmt.Errorf("cannot encode the SARIF log: %w", err)
+	}
+
+	return nil
+}
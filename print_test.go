@@ -3,9 +3,17 @@ package eclint_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/logrusorgru/aurora"
 	"gitlab.com/greut/eclint"
 )
 
@@ -67,3 +75,1263 @@ func TestPrintErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintErrorsCompact(t *testing.T) {
+	ctx := context.TODO()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	opt := &eclint.Option{
+		Stdout:  buf,
+		Compact: true,
+	}
+
+	errs := []error{errors.New("random error")}
+
+	for _, filename := range []string{"a.txt", "b.txt"} {
+		if err := eclint.PrintErrors(ctx, opt, filename, errs); err != nil {
+			t.Fatalf("no errors were expected, got %s", err)
+		}
+	}
+
+	expected := "a.txt:\nrandom error\nb.txt:\nrandom error\n"
+	if buf.String() != expected {
+		t.Errorf("output %q was expected, got %q", expected, buf.String())
+	}
+}
+
+func TestPrintErrorsQuiet(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Errors []error
+		Output string
+	}{
+		{
+			Name:   "no errors",
+			Errors: []error{nil},
+			Output: "",
+		}, {
+			Name: "some errors",
+			Errors: []error{
+				errors.New("random error"),
+				nil,
+				eclint.ValidationError{
+					Line:     []byte("Hello"),
+					Index:    1,
+					Position: 2,
+				},
+			},
+			Output: "some errors: 2 errors\n",
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			opt := &eclint.Option{
+				Stdout:            buf,
+				Quiet:             true,
+				ShowErrorQuantity: 1,
+			}
+
+			if err := eclint.PrintErrors(ctx, opt, tc.Name, tc.Errors); err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			if buf.String() != tc.Output {
+				t.Errorf("output %q was expected, got %q", tc.Output, buf.String())
+			}
+		})
+	}
+}
+
+func TestPrintErrorsTabs(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Line     []byte
+		Position int
+		Column   int
+	}{
+		{
+			Name:     "after tabs",
+			Line:     []byte("\t\tfoo bar\n"),
+			Position: 6,
+			Column:   20,
+		}, {
+			Name:     "on a tab",
+			Line:     []byte("foo\tbar\n"),
+			Position: 3,
+			Column:   3,
+		}, {
+			Name:     "after an aligned tab",
+			Line:     []byte("foo\tbar\n"),
+			Position: 4,
+			Column:   8,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			opt := &eclint.Option{
+				Stdout:     buf,
+				IsTerminal: true,
+			}
+
+			errs := []error{
+				eclint.ValidationError{
+					Line:     tc.Line,
+					Position: tc.Position,
+				},
+			}
+
+			if err := eclint.PrintErrors(ctx, opt, tc.Name, errs); err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			// filename, position and message, then the highlighted line.
+			lines := bytes.Split(buf.Bytes(), []byte("\n"))
+			if len(lines) < 3 {
+				t.Fatalf("the highlighted line was expected, got %q", buf.String())
+			}
+
+			if bytes.ContainsRune(lines[2], '\t') {
+				t.Errorf("tabs were expected to be expanded, got %q", lines[2])
+			}
+
+			if column := bytes.Index(lines[2], []byte("\x1b[")); column != tc.Column {
+				t.Errorf("highlight expected at column %d, got %d in %q", tc.Column, column, lines[2])
+			}
+		})
+	}
+}
+
+func TestPrintErrorsCaret(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Line     []byte
+		Position int
+		Rendered string
+		Caret    string
+	}{
+		{
+			Name:     "after tabs",
+			Line:     []byte("\t\tfoo bar \n"),
+			Position: 9,
+			Caret:    strings.Repeat(" ", 23) + "^",
+		}, {
+			Name:     "on a tab",
+			Line:     []byte("foo\tbar\n"),
+			Position: 3,
+			Caret:    "   ^",
+		}, {
+			Name:     "after a multibyte character",
+			Line:     []byte("\té x\n"),
+			Position: 4,
+			Caret:    "          ^",
+		}, {
+			Name:     "after wide characters",
+			Line:     []byte("日本語です \n"),
+			Position: 15,
+			Caret:    strings.Repeat(" ", 10) + "^",
+		}, {
+			Name:     "on a wide character",
+			Line:     []byte("a日本 \n"),
+			Position: 4,
+			Caret:    "   ^",
+		}, {
+			Name:     "after a tab following wide characters",
+			Line:     []byte("日本\tx \n"),
+			Position: 8,
+			Caret:    strings.Repeat(" ", 9) + "^",
+		}, {
+			Name:     "on the last character without a final newline",
+			Line:     []byte("\tfoo!"),
+			Position: 4,
+			Rendered: "        foo!",
+			Caret:    "           ^",
+		}, {
+			Name:     "after the line without a final newline",
+			Line:     []byte("\tfoo"),
+			Position: 4,
+			Rendered: "        foo ",
+			Caret:    "           ^",
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			opt := &eclint.Option{
+				Stdout:   buf,
+				NoColors: true,
+			}
+
+			errs := []error{
+				eclint.ValidationError{
+					Line:     tc.Line,
+					Position: tc.Position,
+				},
+			}
+
+			if err := eclint.PrintErrors(ctx, opt, tc.Name, errs); err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			// filename, position and message, the line, then the caret.
+			lines := strings.Split(buf.String(), "\n")
+			if len(lines) < 4 {
+				t.Fatalf("the caret line was expected, got %q", buf.String())
+			}
+
+			if tc.Rendered != "" && lines[2] != tc.Rendered {
+				t.Errorf("line %q was expected, got %q", tc.Rendered, lines[2])
+			}
+
+			if lines[3] != tc.Caret {
+				t.Errorf("caret %q was expected under %q, got %q", tc.Caret, lines[2], lines[3])
+			}
+		})
+	}
+}
+
+func TestPrintErrorsColorsNoCaret(t *testing.T) {
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	opt := &eclint.Option{
+		Stdout:     buf,
+		IsTerminal: true,
+		Compact:    true,
+	}
+
+	errs := []error{
+		eclint.ValidationError{
+			Line:     []byte("foo \n"),
+			Position: 3,
+		},
+	}
+
+	if err := eclint.PrintErrors(context.TODO(), opt, "a.txt", errs); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	if strings.Contains(buf.String(), "^") {
+		t.Errorf("no caret was expected with the colors, got %q", buf.String())
+	}
+}
+
+// chunkWriter records each write.
+type chunkWriter struct {
+	chunks []string
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, string(p))
+
+	return len(p), nil
+}
+
+func TestPrintErrorsConcurrent(t *testing.T) {
+	ctx := context.TODO()
+
+	errs := []error{
+		eclint.ValidationError{
+			Rule:     eclint.RuleTrailingWhitespace,
+			Message:  "line has some trailing whitespaces",
+			Line:     []byte("\tfoo \n"),
+			Index:    1,
+			Position: 4,
+		},
+		eclint.ValidationError{
+			Rule:     eclint.RuleFinalNewline,
+			Message:  "the final newline is missing",
+			Line:     []byte("bar"),
+			Index:    2,
+			Position: 3,
+		},
+	}
+
+	w := &chunkWriter{}
+	opt := &eclint.Option{
+		Stdout:     eclint.NewSyncWriter(w),
+		IsTerminal: true,
+	}
+
+	filenames := []string{"a.txt", "b.txt", "c.txt", "d.txt"}
+
+	var wg sync.WaitGroup
+
+	for _, filename := range filenames {
+		wg.Add(1)
+
+		go func(filename string) {
+			defer wg.Done()
+
+			if err := eclint.PrintErrors(ctx, opt, filename, errs); err != nil {
+				t.Errorf("no errors were expected, got %s", err)
+			}
+		}(filename)
+	}
+
+	wg.Wait()
+
+	if len(w.chunks) != len(filenames) {
+		t.Fatalf("one write per file was expected, got %d", len(w.chunks))
+	}
+
+	escape := regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+	for _, chunk := range w.chunks {
+		if strings.Contains(escape.ReplaceAllString(chunk, ""), "\x1b") {
+			t.Errorf("an escape sequence was split in %q", chunk)
+		}
+
+		if strings.Count(chunk, "trailing whitespaces") != 1 || strings.Count(chunk, "final newline") != 1 {
+			t.Errorf("the errors of one file were expected, got %q", chunk)
+		}
+	}
+}
+
+func TestPrintErrorsMultibyte(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Line      []byte
+		Position  int
+		Highlight string
+	}{
+		{
+			Name:      "before and after",
+			Line:      []byte("é x ü!\n"),
+			Position:  3,
+			Highlight: "x",
+		}, {
+			Name:      "on a multibyte character",
+			Line:      []byte("é ü ö\n"),
+			Position:  3,
+			Highlight: "ü",
+		}, {
+			Name:      "within a multibyte character",
+			Line:      []byte("é ü ö\n"),
+			Position:  4,
+			Highlight: "ü",
+		}, {
+			Name:      "last byte without a final newline",
+			Line:      []byte("é x!"),
+			Position:  4,
+			Highlight: "!",
+		}, {
+			Name:      "last multibyte character without a final newline",
+			Line:      []byte("é ü"),
+			Position:  4,
+			Highlight: "ü",
+		}, {
+			Name:      "after the line without a final newline",
+			Line:      []byte("é x"),
+			Position:  4,
+			Highlight: " ",
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			opt := &eclint.Option{
+				Stdout:     buf,
+				IsTerminal: true,
+			}
+
+			errs := []error{
+				eclint.ValidationError{
+					Line:     tc.Line,
+					Position: tc.Position,
+				},
+			}
+
+			if err := eclint.PrintErrors(ctx, opt, tc.Name, errs); err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			lines := strings.Split(buf.String(), "\n")
+			if len(lines) < 3 {
+				t.Fatalf("the highlighted line was expected, got %q", buf.String())
+			}
+
+			highlight := aurora.NewAurora(true).White(tc.Highlight).BgRed().String()
+			prefix, suffix, ok := strings.Cut(lines[2], highlight)
+
+			if !ok {
+				t.Fatalf("%q was expected to be highlighted, got %q", tc.Highlight, lines[2])
+			}
+
+			// The blank highlight of an error after the line isn't part of it.
+			expected := strings.TrimSuffix(string(tc.Line), "\n")
+			if rendered := strings.TrimSuffix(prefix+tc.Highlight+suffix, " "); rendered != expected {
+				t.Errorf("%q was expected, got %q", expected, rendered)
+			}
+		})
+	}
+}
+
+func TestPrintErrorsRuleNames(t *testing.T) {
+	tests := []struct {
+		Name       string
+		IsTerminal bool
+		Expected   string
+	}{
+		{
+			Name:     "no colors",
+			Expected: "2:6: line has some trailing whitespaces [trailing-whitespace]\n",
+		}, {
+			Name:       "colors",
+			IsTerminal: true,
+			Expected:   "line has some trailing whitespaces \x1b[2m[trailing-whitespace]\x1b[0m\n",
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			opt := &eclint.Option{
+				Stdout:        buf,
+				IsTerminal:    tc.IsTerminal,
+				ShowRuleNames: true,
+			}
+
+			errs := []error{
+				eclint.ValidationError{
+					Rule:     eclint.RuleTrailingWhitespace,
+					Message:  "line has some trailing whitespaces",
+					Line:     []byte("Hello \n"),
+					Index:    1,
+					Position: 5,
+				},
+			}
+
+			if err := eclint.PrintErrors(ctx, opt, "file.txt", errs); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(buf.String(), tc.Expected) {
+				t.Errorf("%q was expected, got %q", tc.Expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestPrintErrorsSorted(t *testing.T) {
+	errs := []error{
+		eclint.ValidationError{
+			Rule:     eclint.RuleTrailingWhitespace,
+			Message:  "line has some trailing whitespaces",
+			Line:     []byte("b \n"),
+			Index:    1,
+			Position: 1,
+		},
+		eclint.ValidationError{
+			Rule:     eclint.RuleIndentStyle,
+			Message:  "indentation style mismatch",
+			Line:     []byte(" a\n"),
+			Index:    0,
+			Position: 0,
+		},
+		eclint.ValidationError{
+			Rule:     eclint.RuleFinalNewline,
+			Message:  "the final newline is missing",
+			Line:     []byte("b \n"),
+			Index:    1,
+			Position: 1,
+		},
+		errors.New("cannot read the file"),
+	}
+
+	ctx := context.TODO()
+
+	var expected string
+
+	// Every permutation of the errors is printed the same.
+	permute(errs, 0, func(errs []error) {
+		buf := bytes.NewBuffer(make([]byte, 0, 1024))
+		opt := &eclint.Option{
+			Stdout:   buf,
+			NoColors: true,
+		}
+
+		if err := eclint.PrintErrors(ctx, opt, "file.go", errs); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected == "" {
+			expected = buf.String()
+
+			return
+		}
+
+		if buf.String() != expected {
+			t.Errorf("the output depends on the order of the errors, %q was expected, got %q", expected, buf.String())
+		}
+	})
+
+	last := -1
+
+	for _, message := range []string{"cannot read the file", "1:1: indentation", "2:2: the final", "2:2: line has"} {
+		i := strings.Index(expected, message)
+		if i <= last {
+			t.Errorf("%q was expected after the previous errors, got %q", message, expected)
+		}
+
+		last = i
+	}
+}
+
+// permute calls the function with every permutation of the errors.
+func permute(errs []error, k int, fn func([]error)) {
+	if k == len(errs) {
+		fn(errs)
+
+		return
+	}
+
+	for i := k; i < len(errs); i++ {
+		errs[k], errs[i] = errs[i], errs[k]
+		permute(errs, k+1, fn)
+		errs[k], errs[i] = errs[i], errs[k]
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Errors []error
+	}{
+		{
+			Name:   "no errors",
+			Errors: []error{},
+		}, {
+			Name: "simple error",
+			Errors: []error{
+				errors.New("random error"),
+			},
+		}, {
+			Name: "validation errors",
+			Errors: []error{
+				eclint.ValidationError{
+					Rule:     eclint.RuleTrailingWhitespace,
+					Message:  "line has some trailing whitespaces",
+					Line:     []byte("Hello "),
+					Index:    1,
+					Position: 5,
+					Offset:   12,
+				},
+				eclint.ValidationError{},
+			},
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			opt := &eclint.Option{
+				Stdout: buf,
+				Format: eclint.FormatJSON,
+			}
+
+			p, err := eclint.NewPrinter(opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := p.Print(ctx, tc.Name, tc.Errors); err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			if err := p.Flush(ctx); err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			var result []map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+				t.Fatalf("a valid JSON document was expected, got %s", err)
+			}
+
+			if len(result) != len(tc.Errors) {
+				t.Fatalf("%d errors were expected, got %d", len(tc.Errors), len(result))
+			}
+
+			for i, err := range tc.Errors {
+				if result[i]["filename"] != tc.Name {
+					t.Errorf("filename %q was expected, got %v", tc.Name, result[i]["filename"])
+				}
+
+				var ve eclint.ValidationError
+				if ok := errors.As(err, &ve); ok {
+					if result[i]["line"] != float64(ve.Index+1) || result[i]["column"] != float64(ve.Position+1) {
+						t.Errorf("unexpected position, got %v:%v", result[i]["line"], result[i]["column"])
+					}
+
+					if result[i]["offset"] != float64(ve.Offset) {
+						t.Errorf("offset %d was expected, got %v", ve.Offset, result[i]["offset"])
+					}
+
+					if result[i]["rule"] != ve.Rule {
+						t.Errorf("rule %q was expected, got %v", ve.Rule, result[i]["rule"])
+					}
+
+					if ve.Rule != "" && result[i]["helpUri"] != eclint.RuleHelpURI(ve.Rule) {
+						t.Errorf("the help of the rule was expected, got %v", result[i]["helpUri"])
+					}
+				} else if result[i]["line"] != nil || result[i]["column"] != nil {
+					t.Errorf("no position was expected, got %v:%v", result[i]["line"], result[i]["column"])
+				}
+			}
+		})
+	}
+}
+
+func TestNewPrinterUnknownFormat(t *testing.T) {
+	_, err := eclint.NewPrinter(&eclint.Option{Format: "yaml"})
+	if !errors.Is(err, eclint.ErrUnknownFormat) {
+		t.Errorf("an unknown format error was expected, got %v", err)
+	}
+}
+
+func TestPrintNull(t *testing.T) {
+	ctx := context.TODO()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	opt := &eclint.Option{
+		Stdout: buf,
+		Format: eclint.FormatNull,
+	}
+
+	printer, err := eclint.NewPrinter(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := []error{
+		eclint.ValidationError{
+			Rule:     eclint.RuleTrailingWhitespace,
+			Message:  "line has some trailing whitespace",
+			Filename: "a.txt",
+			Line:     []byte("hello \n"),
+			Index:    0,
+			Position: 5,
+		},
+	}
+
+	if err := printer.Print(ctx, "a.txt", errs); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := printer.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("no output was expected, got %q", buf.String())
+	}
+}
+
+func TestPrintPathStyle(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Name      string
+		PathStyle string
+		Filename  string
+		Expected  string
+	}{
+		{
+			Name:     "as listed",
+			Filename: "testdata/a.txt",
+			Expected: "testdata/a.txt",
+		}, {
+			Name:      "absolute",
+			PathStyle: eclint.PathStyleAbsolute,
+			Filename:  "testdata/a.txt",
+			Expected:  filepath.Join(wd, "testdata", "a.txt"),
+		}, {
+			Name:      "relative",
+			PathStyle: eclint.PathStyleRelative,
+			Filename:  filepath.Join(wd, "testdata", "a.txt"),
+			Expected:  filepath.Join("testdata", "a.txt"),
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			opt := &eclint.Option{
+				Stdout:    buf,
+				Format:    eclint.FormatJSON,
+				PathStyle: tc.PathStyle,
+			}
+
+			printer, err := eclint.NewPrinter(opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := []error{
+				eclint.ValidationError{
+					Rule:     eclint.RuleTrailingWhitespace,
+					Message:  "line has some trailing whitespace",
+					Filename: tc.Filename,
+					Line:     []byte("hello \n"),
+					Position: 5,
+				},
+			}
+
+			if err := printer.Print(ctx, tc.Filename, errs); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := printer.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			var result []map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+				t.Fatalf("a valid JSON document was expected, got %s", err)
+			}
+
+			if len(result) != 1 || result[0]["filename"] != tc.Expected {
+				t.Errorf("filename %q was expected, got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestNewPrinterUnknownPathStyle(t *testing.T) {
+	_, err := eclint.NewPrinter(&eclint.Option{PathStyle: "home"})
+	if !errors.Is(err, eclint.ErrUnknownPathStyle) {
+		t.Errorf("an unknown path style error was expected, got %v", err)
+	}
+}
+
+func TestPrintSarif(t *testing.T) {
+	ctx := context.TODO()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	opt := &eclint.Option{
+		Stdout:  buf,
+		Format:  eclint.FormatSarif,
+		Version: "1.2.3",
+	}
+
+	p, err := eclint.NewPrinter(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := []error{
+		eclint.ValidationError{
+			Rule:     eclint.RuleTrailingWhitespace,
+			Message:  "line has some trailing whitespaces",
+			Line:     []byte("Hello "),
+			Index:    1,
+			Position: 5,
+		},
+		errors.New("random error"),
+	}
+
+	if err := p.Print(ctx, "dir/file.txt", errs); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	if err := p.Flush(ctx); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name    string
+					Version string
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string
+						}
+						Region *struct {
+							StartLine   int
+							StartColumn int
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("a valid JSON document was expected, got %s", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("one SARIF 2.1.0 run was expected, got %q with %d runs", log.Version, len(log.Runs))
+	}
+
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "eclint" || run.Tool.Driver.Version != "1.2.3" {
+		t.Errorf("unexpected driver, got %s %s", run.Tool.Driver.Name, run.Tool.Driver.Version)
+	}
+
+	if len(run.Results) != 2 {
+		t.Fatalf("two results were expected, got %d", len(run.Results))
+	}
+
+	result := run.Results[0]
+	if result.RuleID != eclint.RuleTrailingWhitespace || result.Level != "error" {
+		t.Errorf("unexpected rule or level, got %q %q", result.RuleID, result.Level)
+	}
+
+	location := result.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "dir/file.txt" {
+		t.Errorf("unexpected uri, got %q", location.ArtifactLocation.URI)
+	}
+
+	if location.Region == nil || location.Region.StartLine != 2 || location.Region.StartColumn != 6 {
+		t.Errorf("unexpected region, got %+v", location.Region)
+	}
+
+	if run.Results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("no region was expected for a random error")
+	}
+}
+
+func TestPrintSarifToFile(t *testing.T) {
+	ctx := context.TODO()
+
+	filename := filepath.Join(t.TempDir(), "eclint.sarif")
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opt := &eclint.Option{
+		Stdout:  eclint.NewSyncWriter(f),
+		Format:  eclint.FormatSarif,
+		Version: "1.2.3",
+	}
+
+	p, err := eclint.NewPrinter(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := []error{
+		eclint.ValidationError{
+			Rule:     eclint.RuleFinalNewline,
+			Message:  "the final newline is missing",
+			Line:     []byte("Hello"),
+			Position: 5,
+		},
+	}
+
+	if err := p.Print(ctx, "file.txt", errs); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleID string
+			}
+		}
+	}
+
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("a SARIF log was expected, got %v: %s", err, data)
+	}
+
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 || log.Runs[0].Results[0].RuleID != eclint.RuleFinalNewline {
+		t.Errorf("one %s result was expected, got %s", eclint.RuleFinalNewline, data)
+	}
+}
+
+func TestPrintCheckstyle(t *testing.T) {
+	ctx := context.TODO()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	opt := &eclint.Option{
+		Stdout: buf,
+		Format: eclint.FormatCheckstyle,
+	}
+
+	p, err := eclint.NewPrinter(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := []error{
+		eclint.ValidationError{
+			Rule:     eclint.RuleTrailingWhitespace,
+			Message:  "line has some trailing whitespaces",
+			Line:     []byte("Hello "),
+			Index:    1,
+			Position: 5,
+		},
+		errors.New("random <error>"),
+	}
+
+	if err := p.Print(ctx, "dir/file.txt", errs); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	if err := p.Print(ctx, "clean.txt", nil); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	if err := p.Flush(ctx); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	var log struct {
+		XMLName xml.Name `xml:"checkstyle"`
+		Files   []struct {
+			Name   string `xml:"name,attr"`
+			Errors []struct {
+				Line     int    `xml:"line,attr"`
+				Column   int    `xml:"column,attr"`
+				Severity string `xml:"severity,attr"`
+				Message  string `xml:"message,attr"`
+				Source   string `xml:"source,attr"`
+			} `xml:"error"`
+		} `xml:"file"`
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("a valid XML document was expected, got %s", err)
+	}
+
+	if len(log.Files) != 1 || log.Files[0].Name != "dir/file.txt" {
+		t.Fatalf("only the file having errors was expected, got %+v", log.Files)
+	}
+
+	file := log.Files[0]
+	if len(file.Errors) != 2 {
+		t.Fatalf("two errors were expected, got %d", len(file.Errors))
+	}
+
+	e := file.Errors[0]
+	if e.Line != 2 || e.Column != 6 || e.Severity != "error" || e.Source != "eclint.trailing-whitespace" {
+		t.Errorf("unexpected error, got %+v", e)
+	}
+
+	if e := file.Errors[1]; e.Line != 0 || e.Message != "random <error>" || e.Source != "eclint" {
+		t.Errorf("unexpected error, got %+v", e)
+	}
+}
+
+func TestPrintGitLab(t *testing.T) {
+	ctx := context.TODO()
+
+	type issue struct {
+		Description string `json:"description"`
+		CheckName   string `json:"check_name"`
+		Fingerprint string `json:"fingerprint"`
+		Severity    string `json:"severity"`
+		Location    struct {
+			Path  string `json:"path"`
+			Lines struct {
+				Begin int `json:"begin"`
+			} `json:"lines"`
+		} `json:"location"`
+	}
+
+	report := func(errs []error) []issue {
+		buf := bytes.NewBuffer(make([]byte, 0, 1024))
+		opt := &eclint.Option{
+			Stdout: buf,
+			Format: eclint.FormatGitLab,
+		}
+
+		p, err := eclint.NewPrinter(opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := p.Print(ctx, "dir/file.txt", errs); err != nil {
+			t.Fatalf("no errors were expected, got %s", err)
+		}
+
+		if err := p.Flush(ctx); err != nil {
+			t.Fatalf("no errors were expected, got %s", err)
+		}
+
+		issues := []issue{}
+		if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+			t.Fatalf("a valid JSON document was expected, got %s", err)
+		}
+
+		return issues
+	}
+
+	if issues := report(nil); len(issues) != 0 {
+		t.Errorf("no issues were expected, got %+v", issues)
+	}
+
+	errs := []error{
+		eclint.ValidationError{
+			Rule:     eclint.RuleTrailingWhitespace,
+			Message:  "line has some trailing whitespaces",
+			Line:     []byte("Hello "),
+			Index:    1,
+			Position: 5,
+		},
+		errors.New("random error"),
+	}
+
+	issues := report(errs)
+	if len(issues) != 2 {
+		t.Fatalf("two issues were expected, got %d", len(issues))
+	}
+
+	i := issues[0]
+	if i.CheckName != eclint.RuleTrailingWhitespace || i.Severity != "minor" ||
+		i.Location.Path != "dir/file.txt" || i.Location.Lines.Begin != 2 {
+		t.Errorf("unexpected issue, got %+v", i)
+	}
+
+	if i.Fingerprint == "" || i.Fingerprint == issues[1].Fingerprint {
+		t.Errorf("distinct fingerprints were expected, got %q and %q", i.Fingerprint, issues[1].Fingerprint)
+	}
+
+	if again := report(errs); again[0].Fingerprint != i.Fingerprint {
+		t.Errorf("a stable fingerprint was expected, got %q then %q", i.Fingerprint, again[0].Fingerprint)
+	}
+}
+
+func TestPrintJUnit(t *testing.T) {
+	ctx := context.TODO()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	opt := &eclint.Option{
+		Stdout: buf,
+		Format: eclint.FormatJUnit,
+	}
+
+	p, err := eclint.NewPrinter(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := []error{
+		eclint.ValidationError{
+			Rule:     eclint.RuleTrailingWhitespace,
+			Message:  "line has some trailing whitespaces",
+			Line:     []byte("Hello "),
+			Index:    1,
+			Position: 5,
+		},
+		errors.New("random error"),
+	}
+
+	if err := p.Print(ctx, "dir/file.txt", errs); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	if err := p.Print(ctx, "clean.txt", nil); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	if err := p.Flush(ctx); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	var suite struct {
+		XMLName   xml.Name `xml:"testsuite"`
+		Tests     int      `xml:"tests,attr"`
+		Failures  int      `xml:"failures,attr"`
+		Time      string   `xml:"time,attr"`
+		TestCases []struct {
+			Name    string `xml:"name,attr"`
+			Failure *struct {
+				Message string `xml:"message,attr"`
+			} `xml:"failure"`
+		} `xml:"testcase"`
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatalf("a valid XML document was expected, got %s", err)
+	}
+
+	if suite.Tests != 2 || suite.Failures != 1 || suite.Time == "" {
+		t.Errorf("two tests and one failure were expected, got %d tests, %d failures in %q",
+			suite.Tests, suite.Failures, suite.Time)
+	}
+
+	if len(suite.TestCases) != 2 {
+		t.Fatalf("two test cases were expected, got %d", len(suite.TestCases))
+	}
+
+	failure := suite.TestCases[0].Failure
+	if failure == nil || failure.Message != "2:6: line has some trailing whitespaces; random error" {
+		t.Errorf("unexpected failure, got %+v", failure)
+	}
+
+	if suite.TestCases[1].Name != "clean.txt" || suite.TestCases[1].Failure != nil {
+		t.Errorf("a passing test case was expected, got %+v", suite.TestCases[1])
+	}
+}
+
+func TestPrintGitHub(t *testing.T) {
+	ctx := context.TODO()
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	opt := &eclint.Option{
+		Stdout: buf,
+		Format: eclint.FormatGitHub,
+	}
+
+	p, err := eclint.NewPrinter(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := []error{
+		eclint.ValidationError{
+			Message:  "100% wrong\nreally",
+			Index:    1,
+			Position: 5,
+		},
+		errors.New("random error"),
+	}
+
+	if err := p.Print(ctx, "a,b:c.txt", errs); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	if err := p.Flush(ctx); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	expected := "::error file=a%2Cb%3Ac.txt,line=2,col=6::100%25 wrong%0Areally\n" +
+		"::error file=a%2Cb%3Ac.txt::random error\n"
+
+	if buf.String() != expected {
+		t.Errorf("unexpected output, got %q", buf.String())
+	}
+}
+
+func TestPrintErrorsColors(t *testing.T) {
+	tests := []struct {
+		Name       string
+		IsTerminal bool
+		NoColors   bool
+		HasColors  bool
+	}{
+		{
+			Name:       "terminal",
+			IsTerminal: true,
+			HasColors:  true,
+		}, {
+			Name:       "no terminal",
+			IsTerminal: false,
+			HasColors:  false,
+		}, {
+			Name:       "no colors",
+			IsTerminal: true,
+			NoColors:   true,
+			HasColors:  false,
+		},
+	}
+
+	ctx := context.TODO()
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			opt := &eclint.Option{
+				Stdout:     buf,
+				IsTerminal: tc.IsTerminal,
+				NoColors:   tc.NoColors,
+			}
+
+			errs := []error{
+				eclint.ValidationError{
+					Line:     []byte("Hello"),
+					Index:    1,
+					Position: 2,
+				},
+			}
+
+			if err := eclint.PrintErrors(ctx, opt, tc.Name, errs); err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			if bytes.Contains(buf.Bytes(), []byte("\x1b[")) != tc.HasColors {
+				t.Errorf("colors were expected to be %v, got %q", tc.HasColors, buf.String())
+			}
+		})
+	}
+}
@@ -115,11 +115,22 @@ func probeCharset(ctx context.Context, bs []byte, charset string) (string, error
 
 	var cs string
 	// The first line may contain the BOM for detecting some encodings
-	if charset != Utf8 && charset != Latin1 {
-		cs = detectCharsetUsingBOM(bs)
+	bom := detectCharsetUsingBOM(bs)
+
+	if charset == Utf8 || charset == Latin1 || charset == ASCII {
+		// A BOM cannot be a valid latin1 content, and utf-8 without bom forbids it.
+		if bom != "" {
+			return "", ValidationError{
+				Rule:    RuleCharset,
+				Message: fmt.Sprintf("detected charset %q does not match expected %q", bom, charset),
+			}
+		}
+	} else {
+		cs = bom
 
 		if charset != "" && cs != charset {
 			return "", ValidationError{
+				Rule:    RuleCharset,
 				Message: fmt.Sprintf("no %s prefix were found, got %q", charset, cs),
 			}
 		}
@@ -127,6 +138,11 @@ func probeCharset(ctx context.Context, bs []byte, charset string) (string, error
 		log.V(3).Info("detect using BOM", "charset", charset)
 	}
 
+	// The lines report their bytes above 0x7f, chardet cannot tell ascii apart.
+	if charset == ASCII {
+		return charset, nil
+	}
+
 	if cs == "" && charset != "" {
 		c, err := detectCharset(charset, bs)
 		if err != nil {
@@ -138,6 +154,7 @@ func probeCharset(ctx context.Context, bs []byte, charset string) (string, error
 		// latin1 is a strict subset of utf-8
 		if charset != cs {
 			return "", ValidationError{
+				Rule:    RuleCharset,
 				Message: fmt.Sprintf("detected charset %q does not match expected %q", cs, charset),
 			}
 		}
@@ -192,6 +209,19 @@ func detectCharsetUsingBOM(data []byte) string {
 	return ""
 }
 
+// leadingBOM returns the BOM stripped by the decoder from the start of the content, if any.
+func leadingBOM(r *bufio.Reader) []byte {
+	bs, _ := r.Peek(len(utf8Bom))
+
+	for _, bom := range [][]byte{utf8Bom, utf16leBom, utf16beBom} {
+		if bytes.HasPrefix(bs, bom) {
+			return bom
+		}
+	}
+
+	return nil
+}
+
 // detectCharset detects the file encoding.
 func detectCharset(charset string, data []byte) (string, error) {
 	if charset == "" {
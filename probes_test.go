@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"testing"
 	"unicode/utf16"
 
@@ -104,6 +105,38 @@ func TestProbeCharsetOfBinaryFailure(t *testing.T) {
 			Name:    "utf-8 vs latin1",
 			Charset: "latin1",
 			File:    []byte{'h', 'i', ' ', 0xf0, 0x9f, 0x92, 0xa9, '!'},
+		}, {
+			Name:    "utf-16le vs utf-8",
+			Charset: "utf-8",
+			File:    utf16le("Hello world."),
+		}, {
+			Name:    "utf-16be vs utf-8",
+			Charset: "utf-8",
+			File:    utf16be("Hello world."),
+		}, {
+			Name:    "utf-16le vs latin1",
+			Charset: "latin1",
+			File:    utf16le("Hello world."),
+		}, {
+			Name:    "utf-16be vs utf-16le",
+			Charset: "utf-16le",
+			File:    utf16be("Hello world."),
+		}, {
+			Name:    "utf-8 vs utf-16le",
+			Charset: "utf-16le",
+			File:    []byte("Hello world."),
+		}, {
+			Name:    "utf-8 bom vs utf-8",
+			Charset: "utf-8",
+			File:    []byte{0xef, 0xbb, 0xbf, 'h', 'e', 'l', 'l', 'o', '.'},
+		}, {
+			Name:    "utf-8 bom vs latin1",
+			Charset: "latin1",
+			File:    []byte{0xef, 0xbb, 0xbf, 'h', 'e', 'l', 'l', 'o', '.'},
+		}, {
+			Name:    "utf-8 vs utf-8 bom",
+			Charset: "utf-8 bom",
+			File:    []byte("Hello world."),
 		},
 	}
 
@@ -121,6 +154,11 @@ func TestProbeCharsetOfBinaryFailure(t *testing.T) {
 			if err == nil {
 				t.Errorf("an error was expected, got charset %s, %v", charset, ok)
 			}
+
+			var ve eclint.ValidationError
+			if ok := errors.As(err, &ve); ok && ve.Rule != eclint.RuleCharset {
+				t.Errorf("a charset error was expected, got %s", ve.Rule)
+			}
 		})
 	}
 }
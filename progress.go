@@ -0,0 +1,80 @@
+package eclint
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// progressInterval is the minimum time between two redraws of the counter.
+const progressInterval = 100 * time.Millisecond
+
+// clearLine moves back to the start of the line and erases it.
+const clearLine = "\r\x1b[K"
+
+// Progress draws the number of files linted over the ones listed so far,
+// e.g. "1234/50000 files", in place on a terminal.
+//
+// The files are listed concurrently, while the counter is drawn by the
+// goroutine printing the results. A nil Progress draws nothing.
+type Progress struct {
+	w      io.Writer
+	listed int64
+	done   int64
+	shown  bool
+	last   time.Time
+}
+
+// NewProgress creates the counter drawn on the writer, e.g. the standard error.
+func NewProgress(w io.Writer) *Progress {
+	return &Progress{w: w}
+}
+
+// List counts a file to be linted.
+func (p *Progress) List() {
+	if p == nil {
+		return
+	}
+
+	atomic.AddInt64(&p.listed, 1)
+}
+
+// Hide erases the counter, e.g. before printing the errors of a file.
+func (p *Progress) Hide() error {
+	if p == nil || !p.shown {
+		return nil
+	}
+
+	p.shown = false
+
+	if _, err := io.WriteString(p.w, clearLine); err != nil {
+		return fmt.Errorf("cannot clear the progress: %w", err)
+	}
+
+	return nil
+}
+
+// Advance counts a linted file and redraws the counter, at most every
+// progressInterval unless it was hidden.
+func (p *Progress) Advance() error {
+	if p == nil {
+		return nil
+	}
+
+	done := atomic.AddInt64(&p.done, 1)
+
+	if p.shown && time.Since(p.last) < progressInterval {
+		return nil
+	}
+
+	p.shown = true
+	p.last = time.Now()
+
+	_, err := fmt.Fprintf(p.w, "%s%d/%d files", clearLine, done, atomic.LoadInt64(&p.listed))
+	if err != nil {
+		return fmt.Errorf("cannot draw the progress: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package eclint_test
+
+import (
+	"bytes"
+	"testing"
+
+	"gitlab.com/greut/eclint"
+)
+
+func TestProgress(t *testing.T) {
+	buf := new(bytes.Buffer)
+	p := eclint.NewProgress(buf)
+
+	p.List()
+	p.List()
+
+	if err := p.Advance(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "\r\x1b[K1/2 files" {
+		t.Errorf("the counter was expected, got %q", buf.String())
+	}
+
+	// Until hidden, the counter is not redrawn right away.
+	buf.Reset()
+
+	if err := p.Advance(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("no redraw was expected, got %q", buf.String())
+	}
+
+	if err := p.Hide(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "\r\x1b[K" {
+		t.Errorf("the counter was expected to be cleared, got %q", buf.String())
+	}
+
+	// Hiding twice has no effects.
+	buf.Reset()
+
+	if err := p.Hide(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("nothing was expected, got %q", buf.String())
+	}
+}
+
+func TestProgressNil(t *testing.T) {
+	var p *eclint.Progress
+
+	p.List()
+
+	if err := p.Advance(); err != nil {
+		t.Error(err)
+	}
+
+	if err := p.Hide(); err != nil {
+		t.Error(err)
+	}
+}
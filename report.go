@@ -0,0 +1,56 @@
+package eclint
+
+import (
+	"fmt"
+	"io"
+)
+
+// Report tallies the files checked and the errors found in them.
+type Report struct {
+	Files           int
+	FilesWithErrors int
+	Errors          int
+}
+
+// Add counts the file and its errors.
+func (r *Report) Add(errs []error) {
+	r.Files++
+
+	count := 0
+
+	for _, err := range errs {
+		if err != nil {
+			count++
+		}
+	}
+
+	if count > 0 {
+		r.FilesWithErrors++
+		r.Errors += count
+	}
+}
+
+// Print outputs the footer, e.g. "Checked 1240 files, 37 errors in 12 files."
+func (r *Report) Print(w io.Writer) error {
+	_, err := fmt.Fprintf(
+		w,
+		"Checked %s, %s in %s.\n",
+		plural(r.Files, "file"),
+		plural(r.Errors, "error"),
+		plural(r.FilesWithErrors, "file"),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot write the report: %w", err)
+	}
+
+	return nil
+}
+
+// plural prefixes the noun with the count, adding an s when it isn't one.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
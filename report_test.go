@@ -0,0 +1,48 @@
+package eclint_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"gitlab.com/greut/eclint"
+)
+
+func TestReport(t *testing.T) {
+	report := &eclint.Report{}
+
+	report.Add(nil)
+	report.Add([]error{nil})
+	report.Add([]error{
+		eclint.ValidationError{Rule: eclint.RuleTrailingWhitespace},
+		nil,
+		errors.New("cannot read"),
+	})
+	report.Add([]error{eclint.ValidationError{Rule: eclint.RuleFinalNewline}})
+
+	buf := bytes.NewBuffer(nil)
+	if err := report.Print(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Checked 4 files, 3 errors in 2 files.\n"
+	if buf.String() != expected {
+		t.Errorf("%q was expected, got %q", expected, buf.String())
+	}
+}
+
+func TestReportSingular(t *testing.T) {
+	report := &eclint.Report{}
+
+	report.Add([]error{eclint.ValidationError{Rule: eclint.RuleFinalNewline}})
+
+	buf := bytes.NewBuffer(nil)
+	if err := report.Print(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Checked 1 file, 1 error in 1 file.\n"
+	if buf.String() != expected {
+		t.Errorf("%q was expected, got %q", expected, buf.String())
+	}
+}
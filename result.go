@@ -0,0 +1,119 @@
+package eclint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+)
+
+// Result contains the validation errors found in a file.
+type Result struct {
+	Filename string
+	Errors   []ValidationError
+}
+
+// LintFiles lints the files found within the paths and returns the results
+// instead of printing them.
+func LintFiles(paths []string, opts Option) ([]Result, error) {
+	return LintFilesContext(context.Background(), paths, opts)
+}
+
+// LintFilesContext lists the files like ListFilesContext, skips the ones
+// matching the exclude patterns of the option, and lints the others.
+//
+// There is one result per file, in the order they were listed, even when no
+// errors were found. The errors on the lines not changed are dropped when the
+// option has some Changes. Any error that isn't a ValidationError, e.g. an
+// invalid .editorconfig file, stops the run.
+func LintFilesContext(ctx context.Context, paths []string, opts Option) ([]Result, error) { //nolint:cyclop
+	log := logr.FromContextOrDiscard(ctx)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fileChan, errChan := ListFilesContext(ctx, paths...)
+
+	results := make([]Result, 0)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err() //nolint:wrapcheck
+
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+
+				continue
+			}
+
+			return nil, err
+
+		case filename, ok := <-fileChan:
+			if !ok {
+				// An error may still be waiting.
+				if errChan != nil {
+					if err := <-errChan; err != nil {
+						return nil, err
+					}
+				}
+
+				return results, nil
+			}
+
+			excluded, err := MatchExclude(opts.Exclude, filename)
+			if err != nil {
+				return nil, err
+			}
+
+			if excluded != "" {
+				log.V(4).Info("skipped excluded file", "filename", filename, "exclude", excluded)
+
+				continue
+			}
+
+			if fi, err := os.Stat(filename); err == nil && fi.IsDir() {
+				continue
+			}
+
+			r, err := lintResult(ctx, opts, filename)
+			if err != nil {
+				return nil, err
+			}
+
+			results = append(results, r)
+		}
+	}
+}
+
+// lintResult lints the file and keeps the validation errors.
+func lintResult(ctx context.Context, opts Option, filename string) (Result, error) {
+	errs := Lint(ctx, filename)
+
+	if opts.Changes != nil {
+		errs = opts.Changes.Filter(filename, errs)
+	}
+
+	r := Result{
+		Filename: filename,
+		Errors:   make([]ValidationError, 0, len(errs)),
+	}
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); !ok {
+			return Result{}, fmt.Errorf("cannot lint %s: %w", filename, err)
+		}
+
+		r.Errors = append(r.Errors, ve)
+	}
+
+	return r, nil
+}
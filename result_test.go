@@ -0,0 +1,77 @@
+package eclint_test
+
+import (
+	"testing"
+
+	"gitlab.com/greut/eclint"
+)
+
+func TestLintFiles(t *testing.T) {
+	results, err := eclint.LintFiles([]string{"testdata/insert_final_newline"}, eclint.Option{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{
+		"testdata/insert_final_newline/.editorconfig":          0,
+		"testdata/insert_final_newline/no_final_newline.md":    0,
+		"testdata/insert_final_newline/no_final_newline.txt":   1,
+		"testdata/insert_final_newline/with_final_newline.md":  1,
+		"testdata/insert_final_newline/with_final_newline.txt": 0,
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("%d results were expected, got %v", len(expected), results)
+	}
+
+	for _, r := range results {
+		count, ok := expected[r.Filename]
+		if !ok {
+			t.Errorf("unexpected file %s", r.Filename)
+
+			continue
+		}
+
+		if len(r.Errors) != count {
+			t.Errorf("%s: %d errors were expected, got %v", r.Filename, count, r.Errors)
+		}
+
+		for _, ve := range r.Errors {
+			if ve.Filename != r.Filename || ve.Rule != eclint.RuleFinalNewline {
+				t.Errorf("%s: a final newline error was expected, got %s", r.Filename, ve)
+			}
+		}
+	}
+}
+
+func TestLintFilesExclude(t *testing.T) {
+	opts := eclint.Option{Exclude: []string{"**/*.md", "**/.editorconfig"}}
+
+	results, err := eclint.LintFiles([]string{"testdata/insert_final_newline"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("two results were expected, got %v", results)
+	}
+
+	for _, r := range results {
+		if r.Filename == "testdata/insert_final_newline/with_final_newline.md" {
+			t.Errorf("%s should have been excluded", r.Filename)
+		}
+	}
+}
+
+func TestLintFilesChanges(t *testing.T) {
+	opts := eclint.Option{Changes: eclint.ChangedLines{}}
+
+	results, err := eclint.LintFiles([]string{"testdata/insert_final_newline/no_final_newline.txt"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || len(results[0].Errors) != 0 {
+		t.Errorf("one result without errors was expected, got %v", results)
+	}
+}
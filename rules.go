@@ -0,0 +1,174 @@
+package eclint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownRule represents a rule identifier that doesn't exist.
+var ErrUnknownRule = errors.New("unknown rule")
+
+// Rules returns the identifiers of every rule.
+func Rules() []string {
+	return []string{
+		RuleCharset,
+		RuleByteOrderMark,
+		RuleEndOfLine,
+		RuleIndentStyle,
+		RuleIndentSize,
+		RuleMixedIndentation,
+		RuleTabCharacter,
+		RuleBlockComment,
+		RuleFinalNewline,
+		RuleTrailingWhitespace,
+		RuleTrailingBlankLines,
+		RuleBlankLines,
+		RuleMaxLineLength,
+		RuleMaxLineBytes,
+	}
+}
+
+// rulesHelpURI documents the rules, each one under the anchor made of its identifier.
+const rulesHelpURI = "https://gitlab.com/greut/eclint#rule-"
+
+// RuleHelpURI returns where the rule is documented, none for an unknown rule.
+func RuleHelpURI(rule string) string {
+	for _, r := range Rules() {
+		if r == rule {
+			return rulesHelpURI + rule
+		}
+	}
+
+	return ""
+}
+
+// rulesKey is the context key of the ruleSet.
+type rulesKey struct{}
+
+// ruleSet tells which rules are run, a nil one running them all.
+type ruleSet map[string]bool
+
+// WithRules returns a context where the linting, and fixing, only runs the
+// enabled rules, or all of them when none are given, minus the disabled ones.
+func WithRules(ctx context.Context, enable []string, disable []string) (context.Context, error) {
+	rules := make(ruleSet)
+
+	known := make(map[string]bool)
+	for _, rule := range Rules() {
+		known[rule] = true
+		rules[rule] = len(enable) == 0
+	}
+
+	for _, rule := range enable {
+		if !known[rule] {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownRule, rule)
+		}
+
+		rules[rule] = true
+	}
+
+	// disabling wins over enabling
+	for _, rule := range disable {
+		if !known[rule] {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownRule, rule)
+		}
+
+		rules[rule] = false
+	}
+
+	return context.WithValue(ctx, rulesKey{}, rules), nil
+}
+
+// warningsKey is the context key of the rules reported as warnings.
+type warningsKey struct{}
+
+// WithWarnings returns a context where the errors of the given rules are
+// reported as warnings.
+func WithWarnings(ctx context.Context, rules []string) (context.Context, error) {
+	warnings := make(map[string]bool)
+
+	known := make(map[string]bool)
+	for _, rule := range Rules() {
+		known[rule] = true
+	}
+
+	for _, rule := range rules {
+		if !known[rule] {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownRule, rule)
+		}
+
+		warnings[rule] = true
+	}
+
+	return context.WithValue(ctx, warningsKey{}, warnings), nil
+}
+
+// severity returns the severity of the rule.
+func severity(ctx context.Context, rule string) string {
+	warnings, _ := ctx.Value(warningsKey{}).(map[string]bool)
+	if warnings[rule] {
+		return SeverityWarning
+	}
+
+	return SeverityError
+}
+
+// Severity returns the severity of the error, the ones that aren't
+// validation errors being errors.
+func Severity(err error) string {
+	var ve ValidationError
+	if ok := errors.As(err, &ve); ok && ve.Severity != "" {
+		return ve.Severity
+	}
+
+	return SeverityError
+}
+
+// rulesFromContext returns the rules set by WithRules, if any.
+func rulesFromContext(ctx context.Context) ruleSet {
+	rules, _ := ctx.Value(rulesKey{}).(ruleSet)
+
+	return rules
+}
+
+// enabled tells whether the rule is run.
+func (rs ruleSet) enabled(rule string) bool {
+	if rs == nil {
+		return true
+	}
+
+	return rs[rule]
+}
+
+// expectedCharset returns the charset to probe for, none when its rule is disabled.
+//
+// The probing is still required to detect the binary files and to decode the content.
+func expectedCharset(ctx context.Context, def *definition) string {
+	if !rulesFromContext(ctx).enabled(RuleCharset) {
+		return ""
+	}
+
+	return def.Charset
+}
+
+// without returns the rule set minus the given rules.
+func (rs ruleSet) without(rules map[string]bool) ruleSet {
+	r := make(ruleSet)
+
+	for _, rule := range Rules() {
+		r[rule] = rs.enabled(rule) && !rules[rule]
+	}
+
+	return r
+}
+
+// filter drops the validation error of a disabled rule.
+func (rs ruleSet) filter(err error) error {
+	var ve ValidationError
+	if ok := errors.As(err, &ve); ok && !rs.enabled(ve.Rule) {
+		return nil
+	}
+
+	return err
+}
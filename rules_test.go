@@ -0,0 +1,156 @@
+package eclint_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"gitlab.com/greut/eclint"
+)
+
+func TestWithRules(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Enable  []string
+		Disable []string
+		Rules   []string
+	}{
+		{
+			Name:  "all",
+			Rules: []string{eclint.RuleEndOfLine, eclint.RuleFinalNewline},
+		}, {
+			Name:   "enable",
+			Enable: []string{eclint.RuleEndOfLine},
+			Rules:  []string{eclint.RuleEndOfLine},
+		}, {
+			Name:    "disable",
+			Disable: []string{eclint.RuleEndOfLine},
+			Rules:   []string{eclint.RuleTrailingWhitespace, eclint.RuleFinalNewline},
+		}, {
+			Name:    "disable wins",
+			Enable:  []string{eclint.RuleEndOfLine, eclint.RuleTrailingWhitespace},
+			Disable: []string{eclint.RuleEndOfLine},
+			Rules:   []string{eclint.RuleTrailingWhitespace},
+		},
+	}
+
+	enabled := true
+	file := "Hello \r\nWorld"
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, err := eclint.WithRules(context.TODO(), tc.Enable, tc.Disable)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			def := &editorconfig.Definition{
+				EndOfLine:              "lf",
+				InsertFinalNewline:     &enabled,
+				TrimTrailingWhitespace: &enabled,
+			}
+
+			errs := eclint.LintReaderWithDefinition(ctx, def, "file.txt", strings.NewReader(file), int64(len(file)))
+
+			rules := make([]string, 0, len(errs))
+
+			for _, err := range errs {
+				var ve eclint.ValidationError
+				if ok := errors.As(err, &ve); !ok {
+					t.Fatalf("a validation error was expected, got %s", err)
+				}
+
+				rules = append(rules, ve.Rule)
+			}
+
+			if !cmp.Equal(tc.Rules, rules) {
+				t.Errorf("diff %s", cmp.Diff(tc.Rules, rules))
+			}
+		})
+	}
+}
+
+func TestWithRulesUnknown(t *testing.T) {
+	if _, err := eclint.WithRules(context.TODO(), []string{"indentation"}, nil); !errors.Is(err, eclint.ErrUnknownRule) {
+		t.Errorf("an unknown rule error was expected, got %v", err)
+	}
+
+	if _, err := eclint.WithRules(context.TODO(), nil, []string{"fubar"}); !errors.Is(err, eclint.ErrUnknownRule) {
+		t.Errorf("an unknown rule error was expected, got %v", err)
+	}
+}
+
+func TestWithWarnings(t *testing.T) {
+	ctx, err := eclint.WithWarnings(context.TODO(), []string{eclint.RuleTrailingWhitespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := true
+	file := "Hello \nWorld"
+	def := &editorconfig.Definition{
+		EndOfLine:              "lf",
+		InsertFinalNewline:     &enabled,
+		TrimTrailingWhitespace: &enabled,
+	}
+
+	r := strings.NewReader(file)
+
+	severities := map[string]string{}
+	for _, err := range eclint.LintReaderWithDefinition(ctx, def, "file.txt", r, int64(len(file))) {
+		var ve eclint.ValidationError
+		if ok := errors.As(err, &ve); !ok {
+			t.Fatalf("a validation error was expected, got %s", err)
+		}
+
+		severities[ve.Rule] = eclint.Severity(err)
+	}
+
+	expected := map[string]string{
+		eclint.RuleTrailingWhitespace: eclint.SeverityWarning,
+		eclint.RuleFinalNewline:       eclint.SeverityError,
+	}
+
+	if diff := cmp.Diff(expected, severities); diff != "" {
+		t.Errorf("severities differ %s", diff)
+	}
+
+	if eclint.Severity(errors.New("random error")) != eclint.SeverityError {
+		t.Error("the other errors were expected to be errors")
+	}
+}
+
+func TestWithWarningsUnknown(t *testing.T) {
+	if _, err := eclint.WithWarnings(context.TODO(), []string{"nope"}); !errors.Is(err, eclint.ErrUnknownRule) {
+		t.Errorf("an unknown rule error was expected, got %v", err)
+	}
+}
+
+func TestRuleHelpURI(t *testing.T) {
+	uris := make(map[string]string)
+
+	for _, rule := range eclint.Rules() {
+		uri := eclint.RuleHelpURI(rule)
+		if uri == "" {
+			t.Errorf("%s has no help", rule)
+		}
+
+		if other, ok := uris[uri]; ok {
+			t.Errorf("%s has the same help as %s, got %q", rule, other, uri)
+		}
+
+		uris[uri] = rule
+	}
+
+	if uri := eclint.RuleHelpURI("unknown"); uri != "" {
+		t.Errorf("an unknown rule has no help, got %q", uri)
+	}
+}
@@ -2,23 +2,68 @@ package eclint
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"fmt"
 	"io"
 )
 
+// DefaultMaxLineSize is the length, in bytes, of the longest line ReadLines
+// accepts unless WithMaxLineSize says otherwise.
+const DefaultMaxLineSize = 16 * 1024 * 1024
+
+// maxLineSizeKey is the context key of the longest line accepted.
+type maxLineSizeKey struct{}
+
+// WithMaxLineSize returns a context where ReadLines accepts lines of at most
+// size bytes, terminator included, instead of DefaultMaxLineSize.
+//
+// The buffer starts small and only grows up to that size when a long line
+// needs it.
+func WithMaxLineSize(ctx context.Context, size int) context.Context {
+	return context.WithValue(ctx, maxLineSizeKey{}, size)
+}
+
+// maxLineSizeFromContext returns the longest line accepted.
+func maxLineSizeFromContext(ctx context.Context) int {
+	if size, ok := ctx.Value(maxLineSizeKey{}).(int); ok && size > 0 {
+		return size
+	}
+
+	return DefaultMaxLineSize
+}
+
 // LineFunc is the callback for a line.
 //
-// It returns the line number starting from zero.
+// It returns the line number starting from zero. The line keeps its
+// terminator, see LineEnding, and the last line of the file may have none.
 type LineFunc func(int, []byte, bool) error
 
+// LineEnding returns the terminator of the line, either crlf, cr, or lf, and
+// nil when it has none.
+func LineEnding(line []byte) []byte {
+	switch {
+	case bytes.HasSuffix(line, []byte{cr, lf}):
+		return line[len(line)-2:]
+	case bytes.HasSuffix(line, []byte{cr}), bytes.HasSuffix(line, []byte{lf}):
+		return line[len(line)-1:]
+	default:
+		return nil
+	}
+}
+
 // SplitLines works like bufio.ScanLines while keeping the line endings.
+//
+// A cr ending the data requests more of it, unless at EOF, so a crlf
+// straddling two reads of the buffer is kept as one line ending.
 func SplitLines(data []byte, atEOF bool) (int, []byte, error) {
 	i := 0
 	for i < len(data) {
 		if data[i] == cr {
 			i++
 
-			if i < len(data) && !atEOF {
-				// Request more data
+			if i == len(data) && !atEOF {
+				// Request more data, a lf may follow.
 				return 0, nil, nil
 			}
 
@@ -52,27 +97,91 @@ func SplitLines(data []byte, atEOF bool) (int, []byte, error) {
 // Line numbering starts at 0. Scanner is pretty smart an will reuse
 // its memory structure. This is somehing we explicitly avoid by copying
 // the content to a new slice.
+//
+// The last line is flagged by reading ahead, the fileSize is only kept for
+// compatibility. An empty reader has no lines, the LineFunc isn't called.
+//
+// The lines longer than the DefaultMaxLineSize, see WithMaxLineSize, cannot
+// be read, the bufio.ErrTooLong error ends the returned errors.
 func ReadLines(r io.Reader, fileSize int64, fn LineFunc) []error {
+	return ReadLinesContext(context.Background(), r, fileSize, fn)
+}
+
+// ReadLinesContext is ReadLines stopping when the context is done.
+//
+// The context is checked between the lines, once cancelled the LineFunc
+// isn't called anymore and the context error ends the returned errors.
+func ReadLinesContext(ctx context.Context, r io.Reader, fileSize int64, fn LineFunc) []error {
+	return readLines(ctx, r, fileSize, true, fn)
+}
+
+// ReadLinesNoCopyContext is ReadLinesContext without copying the lines.
+//
+// The line given to the LineFunc is a reused buffer, it is only valid until
+// the LineFunc returns and must neither be retained nor modified.
+// It saves an allocation per line on huge files.
+func ReadLinesNoCopyContext(ctx context.Context, r io.Reader, fileSize int64, fn LineFunc) []error {
+	return readLines(ctx, r, fileSize, false, fn)
+}
+
+func readLines(ctx context.Context, r io.Reader, _ int64, copyLines bool, fn LineFunc) []error {
 	errs := make([]error, 0)
 	sc := bufio.NewScanner(r)
 	sc.Split(SplitLines)
 
-	var read int64
+	maxLineSize := maxLineSizeFromContext(ctx)
+	bufferSize := bufio.MaxScanTokenSize
+
+	if maxLineSize < bufferSize {
+		bufferSize = maxLineSize
+	}
+
+	sc.Buffer(make([]byte, 0, bufferSize), maxLineSize)
+
+	// The line is handed over once the next one is read, so the last one is
+	// known even when the size of the content differs from the file size,
+	// e.g. a stripped BOM or a decoded UTF-16 file.
+	var pending []byte
 
 	i := 0
 
 	for sc.Scan() {
-		l := sc.Bytes()
-		line := make([]byte, len(l))
+		if err := ctx.Err(); err != nil {
+			return append(errs, err)
+		}
+
+		if i > 0 {
+			if err := fn(i-1, pending, false); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		line := sc.Bytes()
 
-		copy(line, l)
+		if copyLines {
+			pending = make([]byte, len(line))
+			copy(pending, line)
+		} else {
+			pending = append(pending[:0], line...)
+		}
+
+		i++
+	}
 
-		read += int64(len(line))
+	// An empty file has no lines at all.
+	if i > 0 {
+		if err := ctx.Err(); err != nil {
+			return append(errs, err)
+		}
 
-		if err := fn(i, line, read == fileSize); err != nil {
+		// The line after the pending one couldn't be read.
+		if err := fn(i-1, pending, sc.Err() == nil); err != nil {
 			errs = append(errs, err)
 		}
-		i++
+	}
+
+	if err := sc.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("cannot read line %d: %w", i+1, err))
 	}
 
 	return errs
@@ -1,13 +1,156 @@
 package eclint_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"gitlab.com/greut/eclint"
 )
 
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		Name  string
+		File  []byte
+		Lines []string
+	}{
+		{
+			Name:  "cr",
+			File:  []byte("a\rb\r"),
+			Lines: []string{"a\r", "b\r"},
+		}, {
+			Name:  "final cr",
+			File:  []byte("a\r"),
+			Lines: []string{"a\r"},
+		}, {
+			Name:  "crlf",
+			File:  []byte("a\r\n"),
+			Lines: []string{"a\r\n"},
+		}, {
+			Name:  "mixed",
+			File:  []byte("a\rb\r\nc\nd"),
+			Lines: []string{"a\r", "b\r\n", "c\n", "d"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			// Reading one byte at a time puts the cr at the end of the buffer.
+			for _, r := range []io.Reader{bytes.NewReader(tc.File), iotest.OneByteReader(bytes.NewReader(tc.File))} {
+				sc := bufio.NewScanner(r)
+				sc.Split(eclint.SplitLines)
+
+				lines := make([]string, 0, len(tc.Lines))
+				for sc.Scan() {
+					lines = append(lines, sc.Text())
+				}
+
+				if err := sc.Err(); err != nil {
+					t.Fatalf("no errors were expected, got %s", err)
+				}
+
+				if fmt.Sprintf("%q", lines) != fmt.Sprintf("%q", tc.Lines) {
+					t.Errorf("lines %q were expected, got %q", tc.Lines, lines)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitLinesBufferBoundary(t *testing.T) {
+	// A cr ending the buffer waits for the next read.
+	advance, token, err := eclint.SplitLines([]byte("a\r"), false)
+	if advance != 0 || token != nil || err != nil {
+		t.Errorf("more data was expected to be requested, got %d, %q, %v", advance, token, err)
+	}
+
+	advance, token, err = eclint.SplitLines([]byte("a\r\nb"), false)
+	if advance != 3 || string(token) != "a\r\n" || err != nil {
+		t.Errorf("the crlf line was expected, got %d, %q, %v", advance, token, err)
+	}
+
+	advance, token, err = eclint.SplitLines([]byte("a\r"), true)
+	if advance != 2 || string(token) != "a\r" || err != nil {
+		t.Errorf("the cr line was expected at EOF, got %d, %q, %v", advance, token, err)
+	}
+
+	// The crlf straddles the initial buffer of the scanner.
+	file := []byte("abc\r\ndef\r\n")
+	sc := bufio.NewScanner(iotest.HalfReader(bytes.NewReader(file)))
+	sc.Buffer(make([]byte, 4), 16)
+	sc.Split(eclint.SplitLines)
+
+	lines := make([]string, 0, 2)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+
+	if err := sc.Err(); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	if fmt.Sprintf("%q", lines) != fmt.Sprintf("%q", []string{"abc\r\n", "def\r\n"}) {
+		t.Errorf("two crlf lines were expected, got %q", lines)
+	}
+}
+
+func TestReadLinesLongLine(t *testing.T) {
+	line := strings.Repeat("a", 2*1024*1024)
+	file := []byte(line + "\nb\n")
+	calls := 0
+
+	errs := eclint.ReadLines(bytes.NewReader(file), -1, func(i int, data []byte, isEOF bool) error {
+		if i == 0 && len(data) != len(line)+1 {
+			t.Errorf("the whole line was expected, got %d bytes", len(data))
+		}
+
+		calls++
+
+		return nil
+	})
+	if len(errs) > 0 {
+		t.Fatalf("no errors were expected, got %v", errs)
+	}
+
+	if calls != 2 {
+		t.Errorf("two lines were expected, got %d", calls)
+	}
+}
+
+func TestReadLinesMaxLineSize(t *testing.T) {
+	ctx := eclint.WithMaxLineSize(context.Background(), 8)
+	file := []byte("a\nbbbbbbbbbbbb\nc\n")
+	lines := make([]string, 0)
+
+	errs := eclint.ReadLinesContext(ctx, bytes.NewReader(file), -1, func(i int, data []byte, isEOF bool) error {
+		if isEOF {
+			t.Errorf("the line %d isn't the last one", i)
+		}
+
+		lines = append(lines, string(data))
+
+		return nil
+	})
+
+	if len(errs) != 1 || !errors.Is(errs[0], bufio.ErrTooLong) {
+		t.Errorf("a too long error was expected, got %v", errs)
+	}
+
+	if fmt.Sprintf("%q", lines) != fmt.Sprintf("%q", []string{"a\n"}) {
+		t.Errorf("only the first line was expected, got %q", lines)
+	}
+}
+
 func TestReadLines(t *testing.T) {
 	tests := []struct {
 		Name     string
@@ -73,3 +216,169 @@ func TestReadLines(t *testing.T) {
 		})
 	}
 }
+
+func TestReadLinesEOF(t *testing.T) {
+	file := []byte("a\nb\nc")
+
+	// The file size may not match the content, e.g. a stripped BOM.
+	for _, fileSize := range []int64{-1, 0, int64(len(file)), int64(len(file)) + 3} {
+		last := -1
+
+		errs := eclint.ReadLines(bytes.NewReader(file), fileSize, func(i int, line []byte, isEOF bool) error {
+			if isEOF {
+				last = i
+			}
+
+			return nil
+		})
+		if len(errs) > 0 {
+			t.Fatalf("no errors were expected, got %v", errs)
+		}
+
+		if last != 2 {
+			t.Errorf("file size %d: the third line was expected last, got %d", fileSize, last)
+		}
+	}
+}
+
+func TestReadLinesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := bytes.NewReader([]byte("a\nb\nc\n"))
+	calls := 0
+
+	errs := eclint.ReadLinesContext(ctx, r, -1, func(i int, line []byte, isEOF bool) error {
+		calls++
+
+		if i == 0 {
+			cancel()
+		}
+
+		return nil
+	})
+
+	if calls != 1 {
+		t.Errorf("the callback was expected once, got %d calls", calls)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("the context error was expected, got %v", errs)
+	}
+}
+
+func TestReadLinesLineEnding(t *testing.T) {
+	tests := []struct {
+		Name    string
+		File    []byte
+		Endings []string
+	}{
+		{
+			Name:    "lf",
+			File:    []byte("a\nb\n"),
+			Endings: []string{"\n", "\n"},
+		}, {
+			Name:    "crlf",
+			File:    []byte("a\r\nb\r\n"),
+			Endings: []string{"\r\n", "\r\n"},
+		}, {
+			Name:    "cr",
+			File:    []byte("a\rb\r"),
+			Endings: []string{"\r", "\r"},
+		}, {
+			Name:    "lf without a final newline",
+			File:    []byte("a\nb"),
+			Endings: []string{"\n", ""},
+		}, {
+			Name:    "crlf without a final newline",
+			File:    []byte("a\r\nb"),
+			Endings: []string{"\r\n", ""},
+		}, {
+			Name:    "cr without a final newline",
+			File:    []byte("a\rb"),
+			Endings: []string{"\r", ""},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			endings := []string{}
+			last := false
+
+			r := bytes.NewReader(tc.File)
+			errs := eclint.ReadLines(r, int64(len(tc.File)), func(i int, line []byte, isEOF bool) error {
+				endings = append(endings, string(eclint.LineEnding(line)))
+				last = isEOF
+
+				return nil
+			})
+			if len(errs) > 0 {
+				t.Fatalf("no errors were expected, got %v", errs)
+			}
+
+			if !last {
+				t.Error("the last line was expected to be flagged")
+			}
+
+			if len(endings) != len(tc.Endings) {
+				t.Fatalf("endings %q were expected, got %q", tc.Endings, endings)
+			}
+
+			for i, ending := range tc.Endings {
+				if endings[i] != ending {
+					t.Errorf("endings %q were expected, got %q", tc.Endings, endings)
+				}
+			}
+		})
+	}
+}
+
+func TestReadLinesNoCopyContext(t *testing.T) {
+	file := []byte("a\r\nb\nc")
+	lines := []string{}
+
+	fn := func(i int, line []byte, isEOF bool) error {
+		lines = append(lines, string(line))
+
+		return nil
+	}
+
+	r := bytes.NewReader(file)
+	errs := eclint.ReadLinesNoCopyContext(context.TODO(), r, int64(len(file)), fn)
+	if len(errs) > 0 {
+		t.Fatalf("no errors were expected, got %v", errs)
+	}
+
+	if strings.Join(lines, "") != string(file) {
+		t.Errorf("lines of %q were expected, got %q", file, lines)
+	}
+}
+
+func benchmarkReadLines(b *testing.B, readLines func(context.Context, io.Reader, int64, eclint.LineFunc) []error) {
+	b.Helper()
+
+	file := bytes.Repeat([]byte("Lorem ipsum dolor sit amet, consectetur adipiscing elit.\n"), 10000)
+	fn := func(i int, line []byte, isEOF bool) error {
+		return nil
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(file)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		readLines(context.TODO(), bytes.NewReader(file), int64(len(file)), fn)
+	}
+}
+
+func BenchmarkReadLines(b *testing.B) {
+	benchmarkReadLines(b, eclint.ReadLinesContext)
+}
+
+func BenchmarkReadLinesNoCopy(b *testing.B) {
+	benchmarkReadLines(b, eclint.ReadLinesNoCopyContext)
+}
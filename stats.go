@@ -0,0 +1,69 @@
+package eclint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// StatsOther is the bucket of the errors not related to any rule, e.g. a file that cannot be read.
+const StatsOther = "other"
+
+// Stats counts the errors found for each rule.
+type Stats map[string]int
+
+// Add classifies the errors by rule.
+func (s Stats) Add(errs []error) {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		rule := StatsOther
+
+		var ve ValidationError
+		if ok := errors.As(err, &ve); ok && ve.Rule != "" {
+			rule = ve.Rule
+		}
+
+		s[rule]++
+	}
+}
+
+// Total is the number of errors of all the rules.
+func (s Stats) Total() int {
+	total := 0
+	for _, count := range s {
+		total += count
+	}
+
+	return total
+}
+
+// Print outputs the table of the rules, sorted by name, and the grand total.
+func (s Stats) Print(w io.Writer) error {
+	rules := make([]string, 0, len(s))
+	for rule := range s {
+		rules = append(rules, rule)
+	}
+
+	sort.Strings(rules)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "rule\tcount")
+
+	for _, rule := range rules {
+		fmt.Fprintf(tw, "%s\t%d\n", rule, s[rule])
+	}
+
+	fmt.Fprintf(tw, "total\t%d\n", s.Total())
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("cannot write the stats: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package eclint_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"gitlab.com/greut/eclint"
+)
+
+func TestStats(t *testing.T) {
+	stats := make(eclint.Stats)
+
+	stats.Add([]error{
+		eclint.ValidationError{Rule: eclint.RuleTrailingWhitespace},
+		nil,
+		eclint.ValidationError{Rule: eclint.RuleTrailingWhitespace},
+	})
+	stats.Add([]error{
+		eclint.ValidationError{Rule: eclint.RuleFinalNewline},
+		errors.New("random error"),
+	})
+
+	if stats[eclint.RuleTrailingWhitespace] != 2 {
+		t.Errorf("two trailing whitespace errors were expected, got %d", stats[eclint.RuleTrailingWhitespace])
+	}
+
+	if stats[eclint.StatsOther] != 1 {
+		t.Errorf("one other error was expected, got %d", stats[eclint.StatsOther])
+	}
+
+	if stats.Total() != 4 {
+		t.Errorf("four errors were expected, got %d", stats.Total())
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	if err := stats.Print(buf); err != nil {
+		t.Fatalf("no errors were expected, got %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("a header, three rules and a total were expected, got %q", buf.String())
+	}
+
+	if fields := strings.Fields(lines[1]); fields[0] != eclint.RuleFinalNewline || fields[1] != "1" {
+		t.Errorf("the rules were expected sorted, got %q", lines[1])
+	}
+
+	if fields := strings.Fields(lines[4]); fields[0] != "total" || fields[1] != "4" {
+		t.Errorf("the grand total was expected last, got %q", lines[4])
+	}
+}
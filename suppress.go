@@ -0,0 +1,130 @@
+package eclint
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Inline directives, within a comment, suppressing the errors.
+const (
+	// DirectiveDisableLine suppresses the errors of its line.
+	DirectiveDisableLine = "eclint-disable-line"
+	// DirectiveDisable suppresses the errors from its line on.
+	DirectiveDisable = "eclint-disable"
+	// DirectiveEnable stops suppressing the errors from its line on.
+	DirectiveEnable = "eclint-enable"
+)
+
+// directive is an inline directive with the rules it applies to, all of them when empty.
+type directive struct {
+	name  string
+	rules []string
+}
+
+// parseDirectives finds the directives of the line.
+//
+// The rules may follow a directive, separated by spaces or commas, until a
+// word that isn't a rule identifier, e.g. "eclint-disable-line max-line-length".
+func parseDirectives(data []byte) []directive {
+	var directives []directive
+
+	prefix := []byte("eclint-")
+
+	for i := bytes.Index(data, prefix); i >= 0; {
+		rest := string(data[i:])
+
+		var d directive
+
+		// the longest directive first
+		for _, name := range []string{DirectiveDisableLine, DirectiveDisable, DirectiveEnable} {
+			if strings.HasPrefix(rest, name) {
+				d.name = name
+				rest = rest[len(name):]
+
+				break
+			}
+		}
+
+		if d.name != "" {
+			d.rules = parseDirectiveRules(rest)
+			directives = append(directives, d)
+		}
+
+		next := bytes.Index(data[i+len(prefix):], prefix)
+		if next < 0 {
+			break
+		}
+
+		i += len(prefix) + next
+	}
+
+	return directives
+}
+
+// parseDirectiveRules reads the rule identifiers following a directive.
+func parseDirectiveRules(rest string) []string {
+	var rules []string
+
+	// the directive must end there
+	if rest != "" && !strings.ContainsAny(rest[:1], " \t,\r\n") {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	for _, rule := range Rules() {
+		known[rule] = true
+	}
+
+	for _, word := range strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ',' || r == '\r' || r == '\n'
+	}) {
+		if !known[word] {
+			break
+		}
+
+		rules = append(rules, word)
+	}
+
+	return rules
+}
+
+// suppressedRules applies the directives of the line and returns the rules
+// suppressed on it.
+//
+// The rules disabled by a block are kept within the definition.
+func (def *definition) suppressedRules(data []byte) map[string]bool {
+	directives := parseDirectives(data)
+	if len(directives) == 0 {
+		return def.Suppressed
+	}
+
+	if def.Suppressed == nil {
+		def.Suppressed = make(map[string]bool)
+	}
+
+	line := make(map[string]bool)
+
+	for _, d := range directives {
+		rules := d.rules
+		if len(rules) == 0 {
+			rules = Rules()
+		}
+
+		for _, rule := range rules {
+			switch d.name {
+			case DirectiveDisableLine:
+				line[rule] = true
+			case DirectiveDisable:
+				def.Suppressed[rule] = true
+			case DirectiveEnable:
+				delete(def.Suppressed, rule)
+			}
+		}
+	}
+
+	for rule := range def.Suppressed {
+		line[rule] = true
+	}
+
+	return line
+}
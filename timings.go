@@ -0,0 +1,61 @@
+package eclint
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Timings records the wall time spent on each file.
+type Timings map[string]time.Duration
+
+// Total is the time spent on all the files.
+//
+// With concurrent workers, it exceeds the duration of the run.
+func (t Timings) Total() time.Duration {
+	var total time.Duration
+	for _, d := range t {
+		total += d
+	}
+
+	return total
+}
+
+// Print outputs the table of the n slowest files, or all of them when n isn't
+// positive, followed by the grand total.
+func (t Timings) Print(w io.Writer, n int) error {
+	files := make([]string, 0, len(t))
+	for filename := range t {
+		files = append(files, filename)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if t[files[i]] != t[files[j]] {
+			return t[files[i]] > t[files[j]]
+		}
+
+		return files[i] < files[j]
+	})
+
+	if n > 0 && n < len(files) {
+		files = files[:n]
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "file\ttime")
+
+	for _, filename := range files {
+		fmt.Fprintf(tw, "%s\t%s\n", filename, t[filename].Round(time.Microsecond))
+	}
+
+	fmt.Fprintf(tw, "total\t%s\n", t.Total().Round(time.Microsecond))
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("cannot write the timings: %w", err)
+	}
+
+	return nil
+}
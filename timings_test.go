@@ -0,0 +1,69 @@
+package eclint_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/greut/eclint"
+)
+
+func TestTimings(t *testing.T) {
+	timings := eclint.Timings{
+		"a.txt": 2 * time.Millisecond,
+		"b.txt": 5 * time.Millisecond,
+		"c.txt": time.Millisecond,
+	}
+
+	if timings.Total() != 8*time.Millisecond {
+		t.Errorf("8ms were expected, got %s", timings.Total())
+	}
+
+	tests := []struct {
+		Name  string
+		N     int
+		Files []string
+	}{
+		{
+			Name:  "all",
+			N:     0,
+			Files: []string{"b.txt", "a.txt", "c.txt"},
+		}, {
+			Name:  "slowest",
+			N:     2,
+			Files: []string{"b.txt", "a.txt"},
+		}, {
+			Name:  "more than the files",
+			N:     5,
+			Files: []string{"b.txt", "a.txt", "c.txt"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(make([]byte, 0, 1024))
+			if err := timings.Print(buf, tc.N); err != nil {
+				t.Fatalf("no errors were expected, got %s", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != len(tc.Files)+2 {
+				t.Fatalf("a header, %d files and a total were expected, got %q", len(tc.Files), buf.String())
+			}
+
+			for i, filename := range tc.Files {
+				if fields := strings.Fields(lines[i+1]); fields[0] != filename {
+					t.Errorf("%s was expected at row %d, got %q", filename, i+1, lines[i+1])
+				}
+			}
+
+			if fields := strings.Fields(lines[len(lines)-1]); fields[0] != "total" || fields[1] != "8ms" {
+				t.Errorf("the grand total was expected last, got %q", lines[len(lines)-1])
+			}
+		})
+	}
+}
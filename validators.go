@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
 )
 
 const (
@@ -21,16 +25,46 @@ var (
 	utf32beBom = []byte{0, 0, 0xfe, 0xff} //nolint:gochecknoglobals
 )
 
+// Severities of the validation errors.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Rule identifiers of the validation errors.
+const (
+	RuleCharset            = "charset"
+	RuleEndOfLine          = "end-of-line"
+	RuleIndentStyle        = "indent-style"
+	RuleIndentSize         = "indent-size"
+	RuleFinalNewline       = "final-newline"
+	RuleTrailingWhitespace = "trailing-whitespace"
+	RuleBlockComment       = "block-comment"
+	RuleMaxLineLength      = "max-line-length"
+	RuleBlankLines         = "max-consecutive-blank-lines"
+	RuleTrailingBlankLines = "trailing-blank-lines"
+	RuleMixedIndentation   = "mixed-indentation"
+	RuleByteOrderMark      = "byte-order-mark"
+	RuleTabCharacter       = "tab-character"
+	RuleMaxLineBytes       = "max-line-bytes"
+)
+
 // ErrConfiguration represents an error in the editorconfig value.
 var ErrConfiguration = errors.New("configuration error")
 
 // ValidationError is a rich type containing information about the error.
 type ValidationError struct {
+	Rule string
+	// Severity is either SeverityError, the default when empty, or SeverityWarning.
+	Severity string
 	Message  string
 	Filename string
 	Line     []byte
 	Index    int
 	Position int
+	// Offset is the byte offset of the error within the file, its BOM
+	// included, or within the decompressed content of a gzip file.
+	Offset int
 }
 
 func (e ValidationError) String() string {
@@ -43,27 +77,42 @@ func (e ValidationError) Error() string {
 }
 
 // endOfLines checks the line ending.
+//
+// The error points to the first byte of the wrong line ending, e.g. the
+// stray cr of a crlf when lf is expected. A line without any, the last one,
+// is left to insert_final_newline.
 func endOfLine(eol string, data []byte) error {
+	position := len(data) - len(LineEnding(data))
+	terminated := hasEndOfLine(data)
+
 	switch eol {
 	case "lf":
-		if !bytes.HasSuffix(data, []byte{lf}) || bytes.HasSuffix(data, []byte{cr, lf}) {
+		if terminated && (!bytes.HasSuffix(data, []byte{lf}) || bytes.HasSuffix(data, []byte{cr, lf})) {
 			return ValidationError{
+				Rule:     RuleEndOfLine,
 				Message:  "line does not end with lf (`\\n`)",
-				Position: len(data),
+				Position: position,
 			}
 		}
 	case "crlf":
-		if !bytes.HasSuffix(data, []byte{cr, lf}) && !bytes.HasSuffix(data, []byte{0x00, cr, 0x00, lf}) {
+		if terminated && !bytes.HasSuffix(data, []byte{cr, lf}) && !bytes.HasSuffix(data, []byte{0x00, cr, 0x00, lf}) {
 			return ValidationError{
+				Rule:     RuleEndOfLine,
 				Message:  "line does not end with crlf (`\\r\\n`)",
-				Position: len(data),
+				Position: position,
 			}
 		}
 	case "cr":
-		if !bytes.HasSuffix(data, []byte{cr}) {
+		if terminated && !bytes.HasSuffix(data, []byte{cr}) {
+			// The lf of a crlf is the stray byte.
+			if bytes.HasSuffix(data, []byte{cr, lf}) {
+				position = len(data) - 1
+			}
+
 			return ValidationError{
+				Rule:     RuleEndOfLine,
 				Message:  "line does not end with cr (`\\r`)",
-				Position: len(data),
+				Position: position,
 			}
 		}
 	default:
@@ -107,8 +156,14 @@ func indentStyle(style string, size int, data []byte) error {
 		}
 
 		if data[i] == x {
+			message := "indentation must use tabs, found space"
+			if style == SpaceValue {
+				message = fmt.Sprintf("indentation must be a multiple of %d spaces, found tab", size)
+			}
+
 			return ValidationError{
-				Message:  fmt.Sprintf("indentation style mismatch expected %q (%s) got %q", c, style, x),
+				Rule:     RuleIndentStyle,
+				Message:  message,
 				Position: i,
 			}
 		}
@@ -118,7 +173,8 @@ func indentStyle(style string, size int, data []byte) error {
 		}
 
 		return ValidationError{
-			Message:  fmt.Sprintf("indentation size doesn't match expected %d, got %d", size, i),
+			Rule:     RuleIndentSize,
+			Message:  fmt.Sprintf("indentation must be a multiple of %d spaces, found %d", size, i),
 			Position: i,
 		}
 	}
@@ -126,16 +182,179 @@ func indentStyle(style string, size int, data []byte) error {
 	return nil
 }
 
+// checkMixedIndentation checks that the leading whitespaces don't mix tabs and
+// spaces against the indentation style, regardless of the indentation size.
+//
+// A tab is never expected among spaces, while spaces may follow the tabs, for
+// alignment, but not precede them. A line only indented with the wrong
+// character isn't mixed, it's left to the indentation style.
+func checkMixedIndentation(style string, data []byte) error {
+	indentation := data[:len(data)-len(bytes.TrimLeft(data, " \t"))]
+	tabs := bytes.IndexByte(indentation, tab)
+	spaces := bytes.IndexByte(indentation, space)
+
+	switch {
+	case style == SpaceValue && tabs >= 0 && spaces >= 0:
+		return ValidationError{
+			Rule:     RuleMixedIndentation,
+			Message:  "mixed indentation, a tab was found using spaces",
+			Position: tabs,
+		}
+	case style == TabValue && spaces >= 0 && bytes.LastIndexByte(indentation, tab) > spaces:
+		return ValidationError{
+			Rule:     RuleMixedIndentation,
+			Message:  "mixed indentation, spaces were found before a tab",
+			Position: spaces,
+		}
+	}
+
+	return nil
+}
+
+// checkTabCharacters lints every tab of the line, e.g. the ones aligning a
+// trailing comment, and not only the indentation ones.
+func checkTabCharacters(data []byte) []ValidationError {
+	var errs []ValidationError
+
+	for i, b := range data {
+		if b == tab {
+			errs = append(errs, ValidationError{
+				Rule:     RuleTabCharacter,
+				Message:  "tab character was found, spaces were expected",
+				Position: i,
+			})
+		}
+	}
+
+	return errs
+}
+
+// checkByteOrderMark checks that no BOM is found within the line.
+//
+// The lines of the 8-bit charsets are the raw bytes, where the utf-8 and
+// utf-16 BOMs are searched for. The utf-16 lines are decoded to utf-8, so a
+// stray utf-16 BOM looks like a utf-8 one. The leading BOM of the file isn't
+// checked here.
+func checkByteOrderMark(index int, data []byte) error {
+	boms := [][]byte{utf8Bom, utf16leBom, utf16beBom}
+
+	offset := 0
+
+	if index == 0 {
+		for _, bom := range boms {
+			if bytes.HasPrefix(data, bom) {
+				offset = len(bom)
+
+				break
+			}
+		}
+	}
+
+	position := -1
+
+	for _, bom := range boms {
+		if i := bytes.Index(data[offset:], bom); i >= 0 && (position < 0 || i < position) {
+			position = i
+		}
+	}
+
+	if position >= 0 {
+		return ValidationError{
+			Rule:     RuleByteOrderMark,
+			Message:  "a byte order mark (BOM) was found after the start of the file",
+			Position: offset + position,
+		}
+	}
+
+	return nil
+}
+
+// checkUTF8 checks that the line is made of valid UTF-8 sequences.
+//
+// The error points to the first byte of the invalid sequence, e.g. a stray
+// continuation byte or a truncated multibyte character.
+func checkUTF8(data []byte) error {
+	if utf8.Valid(data) {
+		return nil
+	}
+
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			return ValidationError{
+				Rule:     RuleCharset,
+				Message:  fmt.Sprintf("invalid UTF-8 sequence starting with byte 0x%02x", data[i]),
+				Position: i,
+			}
+		}
+
+		i += size
+	}
+
+	return nil
+}
+
+// checkLatin1 checks that the line doesn't contain UTF-8 multibyte characters.
+//
+// Every byte is a valid latin1 character, but a valid UTF-8 multibyte
+// sequence betrays a file that isn't really latin1.
+func checkLatin1(data []byte) error {
+	for i := 0; i < len(data); {
+		if data[i] < utf8.RuneSelf {
+			i++
+
+			continue
+		}
+
+		r, size := utf8.DecodeRune(data[i:])
+		if size > 1 {
+			return ValidationError{
+				Rule:     RuleCharset,
+				Message:  fmt.Sprintf("the UTF-8 character %q was found, latin1 was expected", r),
+				Position: i,
+			}
+		}
+
+		i += size
+	}
+
+	return nil
+}
+
+// checkASCII checks that the line only contains bytes up to 0x7f.
+func checkASCII(data []byte) error {
+	for i, b := range data {
+		if b >= utf8.RuneSelf {
+			return ValidationError{
+				Rule:     RuleCharset,
+				Message:  fmt.Sprintf("non-ASCII byte 0x%02x was found, ascii was expected", b),
+				Position: i,
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasEndOfLine tells whether the line is terminated by a cr or a lf.
+func hasEndOfLine(data []byte) bool {
+	return len(LineEnding(data)) > 0
+}
+
 // checkInsertFinalNewline checks whenever the final line contains a newline or not.
+//
+// The final line is the last one read, an empty line ending the file being
+// a line like any other, e.g. "a\n\n" ends with the "\n" line. A file ending
+// with a newline has no line after it, so "a\n" ends with the "a\n" line.
 func checkInsertFinalNewline(data []byte, insertFinalNewline bool) error {
 	if len(data) == 0 {
 		return nil
 	}
 
-	lastChar := data[len(data)-1]
-	if lastChar != cr && lastChar != lf {
+	if !hasEndOfLine(data) {
 		if insertFinalNewline {
 			return ValidationError{
+				Rule:     RuleFinalNewline,
 				Message:  "the final newline is missing",
 				Position: len(data),
 			}
@@ -143,8 +362,9 @@ func checkInsertFinalNewline(data []byte, insertFinalNewline bool) error {
 	} else {
 		if !insertFinalNewline {
 			return ValidationError{
+				Rule:     RuleFinalNewline,
 				Message:  "an extraneous final newline was found",
-				Position: len(data),
+				Position: len(bytes.TrimRight(data, "\r\n")),
 			}
 		}
 	}
@@ -161,6 +381,7 @@ func checkTrimTrailingWhitespace(data []byte) error {
 
 		if data[i] == space || data[i] == tab {
 			return ValidationError{
+				Rule:     RuleTrailingWhitespace,
 				Message:  "line has some trailing whitespaces",
 				Position: i,
 			}
@@ -172,6 +393,40 @@ func checkTrimTrailingWhitespace(data []byte) error {
 	return nil
 }
 
+// isBlankLine tells whether the line is empty or only made of whitespaces.
+func isBlankLine(data []byte) bool {
+	return len(bytes.Trim(data, " \t\r\n")) == 0
+}
+
+// checkMaxConsecutiveBlankLines lints the line making the run of blank lines
+// exceed the maximum.
+func checkMaxConsecutiveBlankLines(maxBlankLines int, blankLines int) error {
+	if blankLines == maxBlankLines+1 {
+		return ValidationError{
+			Rule:    RuleBlankLines,
+			Message: fmt.Sprintf("more than %d consecutive blank lines", maxBlankLines),
+		}
+	}
+
+	return nil
+}
+
+// checkTrimTrailingBlankLines lints the blank lines ending the file, the
+// given line, starting at the offset, being the first of them, if any.
+func checkTrimTrailingBlankLines(index int, offset int, data []byte) error {
+	if data == nil {
+		return nil
+	}
+
+	return ValidationError{
+		Rule:    RuleTrailingBlankLines,
+		Message: "file ends with some blank lines",
+		Line:    data,
+		Index:   index,
+		Offset:  offset,
+	}
+}
+
 // isBlockCommentStart tells you when a block comment started on this line.
 func isBlockCommentStart(start []byte, data []byte) bool {
 	for i := 0; i < len(data); i++ {
@@ -185,30 +440,82 @@ func isBlockCommentStart(start []byte, data []byte) bool {
 	return false
 }
 
+// hasIndentedPrefix tells whether the line starts with one of the prefixes, once indented.
+func hasIndentedPrefix(markers [][]byte, data []byte) bool {
+	for _, marker := range markers {
+		if isBlockCommentStart(marker, data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isInsideBlockString tells whether a block string remains open at the end of the line.
+//
+// The delimiters may be the same, e.g. the triple quotes of Python.
+func isInsideBlockString(start []byte, end []byte, inside bool, data []byte) bool {
+	for len(data) > 0 {
+		delimiter := start
+		if inside {
+			delimiter = end
+		}
+
+		i := bytes.Index(data, delimiter)
+		if i < 0 {
+			break
+		}
+
+		data = data[i+len(delimiter):]
+		inside = !inside
+	}
+
+	return inside
+}
+
 // checkBlockComment checks the line is a valid block comment.
-func checkBlockComment(i int, prefix []byte, data []byte) error {
+//
+// The block_comment prefix is allowed one space of alignment on top of the
+// indentation, e.g. the " *" of a Javadoc using tabs.
+func checkBlockComment(style string, size int, prefix []byte, data []byte) error {
+	i := 0
 	for ; i < len(data); i++ {
-		if data[i] == space || data[i] == tab {
-			continue
+		if data[i] != space && data[i] != tab {
+			break
 		}
+	}
 
-		if !bytes.HasPrefix(data[i:], prefix) {
-			return ValidationError{
-				Message:  fmt.Sprintf("block_comment prefix %q was expected inside a block comment", string(prefix)),
-				Position: i,
-			}
+	if !bytes.HasPrefix(data[i:], prefix) {
+		return ValidationError{
+			Rule:     RuleBlockComment,
+			Message:  fmt.Sprintf("block_comment prefix %q was expected inside a block comment", string(prefix)),
+			Position: i,
 		}
+	}
 
-		break
+	if i == 0 || data[i-1] != space {
+		return indentStyle(style, size, data)
+	}
+
+	// Drop the alignment space and check the indentation again.
+	line := make([]byte, 0, len(data)-1)
+	line = append(line, data[:i-1]...)
+	line = append(line, data[i:]...)
+
+	if err := indentStyle(style, size, line); err != nil {
+		// Report the error on the original line.
+		return indentStyle(style, size, data)
 	}
 
 	return nil
 }
 
 // isBlockCommentEnd tells you when a block comment end on this line.
+//
+// The trailing whitespace is skipped, its own rule reports it.
 func isBlockCommentEnd(end []byte, data []byte) bool {
 	for i := len(data) - 1; i > 0; i-- {
-		if data[i] == cr || data[i] == lf {
+		if data[i] == cr || data[i] == lf || data[i] == space || data[i] == tab {
 			continue
 		}
 
@@ -220,34 +527,41 @@ func isBlockCommentEnd(end []byte, data []byte) bool {
 
 // MaxLineLength checks the length of a given line.
 //
-// It assumes UTF-8 and will count as one runes. The first byte has no prefix
-// 0xxxxxxx, 110xxxxx, 1110xxxx, 11110xxx, 111110xx, etc. and the following byte
-// the 10xxxxxx prefix which are skipped.
+// It assumes UTF-8 and counts the columns used to display each rune, see
+// runeWidth. A tab moves to the next multiple of the tab width, like
+// editors render it. The position of the error is the byte where the limit
+// is first exceeded.
 func MaxLineLength(maxLength int, tabWidth int, data []byte) error {
+	if tabWidth <= 0 {
+		tabWidth = DefaultTabWidth
+	}
+
 	length := 0
-	breakingPosition := 0
+	breakingPosition := -1
 
-	for i := 0; i < len(data); i++ {
+	for i := 0; i < len(data); {
 		if data[i] == cr || data[i] == lf {
 			break
 		}
 
-		switch {
-		case data[i] == tab:
-			length += tabWidth
-		case (data[i] >> 6) == 0b10:
-			// skip 0x10xxxxxx that are UTF-8 continuation markers
-		default:
-			length++
+		r, size := utf8.DecodeRune(data[i:])
+
+		if r == tab {
+			length += tabWidth - length%tabWidth
+		} else {
+			length += runeWidth(r)
 		}
 
-		if length > maxLength && breakingPosition == 0 {
+		if length > maxLength && breakingPosition < 0 {
 			breakingPosition = i
 		}
+
+		i += size
 	}
 
 	if length > maxLength {
 		return ValidationError{
+			Rule:     RuleMaxLineLength,
 			Message:  fmt.Sprintf("line is too long (%d > %d)", length, maxLength),
 			Position: breakingPosition,
 		}
@@ -255,3 +569,20 @@ func MaxLineLength(maxLength int, tabWidth int, data []byte) error {
 
 	return nil
 }
+
+// runeWidth returns the number of columns used to display the rune.
+//
+// The East Asian wide and fullwidth characters use two columns, while the
+// combining marks and the format characters, e.g. zero width joiner, use none.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+
+	switch width.LookupRune(r).Kind() { //nolint:exhaustive
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	default:
+		return 1
+	}
+}
@@ -23,6 +23,14 @@ func TestEndOfLine(t *testing.T) {
 			Name:      "cr",
 			EndOfLine: "cr",
 			Line:      []byte("\r"),
+		}, {
+			Name:      "no line ending",
+			EndOfLine: "lf",
+			Line:      []byte("hello"),
+		}, {
+			Name:      "no line ending with crlf",
+			EndOfLine: "crlf",
+			Line:      []byte("hello"),
 		},
 	}
 	for _, tc := range tests {
@@ -48,32 +56,32 @@ func TestEndOfLineFailures(t *testing.T) {
 			Name:      "cr instead of crlf",
 			EndOfLine: "crlf",
 			Line:      []byte("\r"),
-			Position:  1,
+			Position:  0,
 		}, {
 			Name:      "lf instead of crlf",
 			EndOfLine: "crlf",
 			Line:      []byte("[*]\n"),
-			Position:  4,
+			Position:  3,
 		}, {
 			Name:      "cr instead of lf",
 			EndOfLine: "lf",
 			Line:      []byte("\r"),
-			Position:  1,
+			Position:  0,
 		}, {
 			Name:      "crlf instead of lf",
 			EndOfLine: "lf",
-			Line:      []byte("\r\n"),
-			Position:  2,
+			Line:      []byte("hello\r\n"),
+			Position:  5,
 		}, {
 			Name:      "crlf instead of cr",
 			EndOfLine: "cr",
 			Line:      []byte("\r\n"),
-			Position:  2,
+			Position:  1,
 		}, {
 			Name:      "lf instead of cr",
 			EndOfLine: "cr",
 			Line:      []byte("hello\n"),
-			Position:  6,
+			Position:  5,
 		}, {
 			Name:      "unknown eol",
 			EndOfLine: "lfcr",
@@ -264,25 +272,163 @@ func TestIndentStyleFailure(t *testing.T) {
 	}
 }
 
+func TestIndentStyleMessage(t *testing.T) {
+	tests := []struct {
+		Name        string
+		IndentSize  int
+		IndentStyle string
+		Line        []byte
+		Message     string
+		Position    int
+	}{
+		{
+			Name:        "three spaces",
+			IndentSize:  4,
+			IndentStyle: "space",
+			Line:        []byte("   ."),
+			Message:     "indentation must be a multiple of 4 spaces, found 3",
+			Position:    3,
+		}, {
+			Name:        "tab under space style",
+			IndentSize:  4,
+			IndentStyle: "space",
+			Line:        []byte("    \t."),
+			Message:     "indentation must be a multiple of 4 spaces, found tab",
+			Position:    4,
+		}, {
+			Name:        "space under tab style",
+			IndentSize:  4,
+			IndentStyle: "tab",
+			Line:        []byte("\t ."),
+			Message:     "indentation must use tabs, found space",
+			Position:    1,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := indentStyle(tc.IndentStyle, tc.IndentSize, tc.Line)
+
+			var ve ValidationError
+			if ok := errors.As(err, &ve); !ok {
+				t.Fatalf("a validation error was expected, got %v", err)
+			}
+
+			if ve.Message != tc.Message || ve.Position != tc.Position {
+				t.Errorf("%q at %d was expected, got %q at %d", tc.Message, tc.Position, ve.Message, ve.Position)
+			}
+		})
+	}
+}
+
+func TestCheckMixedIndentation(t *testing.T) {
+	tests := []struct {
+		Name        string
+		IndentStyle string
+		Line        []byte
+		Position    int
+	}{
+		{
+			Name:        "tab then spaces using spaces",
+			IndentStyle: "space",
+			Line:        []byte("\t  code"),
+			Position:    0,
+		}, {
+			Name:        "tab then spaces using tabs",
+			IndentStyle: "tab",
+			Line:        []byte("\t  code"),
+			Position:    -1,
+		}, {
+			Name:        "spaces then tab using spaces",
+			IndentStyle: "space",
+			Line:        []byte("  \tcode"),
+			Position:    2,
+		}, {
+			Name:        "spaces then tab using tabs",
+			IndentStyle: "tab",
+			Line:        []byte("  \tcode"),
+			Position:    0,
+		}, {
+			Name:        "tabs using spaces",
+			IndentStyle: "space",
+			Line:        []byte("\t\tcode"),
+			Position:    -1,
+		}, {
+			Name:        "tabs then a space using spaces",
+			IndentStyle: "space",
+			Line:        []byte("\t\t code"),
+			Position:    0,
+		}, {
+			Name:        "spaces using tabs",
+			IndentStyle: "tab",
+			Line:        []byte("    code"),
+			Position:    -1,
+		}, {
+			Name:        "spaces using spaces",
+			IndentStyle: "space",
+			Line:        []byte("    code\t// comment"),
+			Position:    -1,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := checkMixedIndentation(tc.IndentStyle, tc.Line)
+			if tc.Position < 0 {
+				if err != nil {
+					t.Errorf("no errors were expected, got %s", err)
+				}
+
+				return
+			}
+
+			var ve ValidationError
+			if ok := errors.As(err, &ve); !ok {
+				t.Fatalf("a validation error was expected, got %v", err)
+			}
+
+			if ve.Rule != RuleMixedIndentation || ve.Position != tc.Position {
+				t.Errorf("%s error expected at %d, got %s at %d", RuleMixedIndentation, tc.Position, ve.Rule, ve.Position)
+			}
+		})
+	}
+}
+
 func TestCheckBlockComment(t *testing.T) {
 	tests := []struct {
-		Name     string
-		Position int
-		Prefix   []byte
-		Line     []byte
+		Name        string
+		IndentStyle string
+		IndentSize  int
+		Prefix      []byte
+		Line        []byte
 	}{
 		{
-			Name:     "Java",
-			Position: 5,
-			Prefix:   []byte{'*'},
-			Line:     []byte("\t\t\t\t *\r\n"),
+			Name:        "Java",
+			IndentStyle: "tab",
+			IndentSize:  1,
+			Prefix:      []byte{'*'},
+			Line:        []byte("\t\t\t\t *\r\n"),
+		}, {
+			Name:        "Java end",
+			IndentStyle: "tab",
+			IndentSize:  1,
+			Prefix:      []byte{'*'},
+			Line:        []byte("\t */\n"),
+		}, {
+			Name:        "spaces",
+			IndentStyle: "space",
+			IndentSize:  4,
+			Prefix:      []byte{'*'},
+			Line:        []byte("     * comment\n"),
 		},
 	}
 	for _, tc := range tests {
 		tc := tc
 		t.Run(tc.Name, func(t *testing.T) {
 			t.Parallel()
-			err := checkBlockComment(tc.Position, tc.Prefix, tc.Line)
+			err := checkBlockComment(tc.IndentStyle, tc.IndentSize, tc.Prefix, tc.Line)
 			if err != nil {
 				t.Errorf("no errors were expected, got %s", err)
 			}
@@ -290,6 +436,63 @@ func TestCheckBlockComment(t *testing.T) {
 	}
 }
 
+func TestCheckBlockCommentFailure(t *testing.T) {
+	tests := []struct {
+		Name        string
+		IndentStyle string
+		IndentSize  int
+		Prefix      []byte
+		Line        []byte
+		Rule        string
+	}{
+		{
+			Name:        "two alignment spaces",
+			IndentStyle: "tab",
+			IndentSize:  1,
+			Prefix:      []byte{'*'},
+			Line:        []byte("\t  *\n"),
+			Rule:        RuleIndentStyle,
+		}, {
+			Name:        "spaces instead of tabs",
+			IndentStyle: "tab",
+			IndentSize:  1,
+			Prefix:      []byte{'*'},
+			Line:        []byte("     *\n"),
+			Rule:        RuleIndentStyle,
+		}, {
+			Name:        "wrong indentation size",
+			IndentStyle: "space",
+			IndentSize:  4,
+			Prefix:      []byte{'*'},
+			Line:        []byte("   *\n"),
+			Rule:        RuleIndentSize,
+		}, {
+			Name:        "missing prefix",
+			IndentStyle: "tab",
+			IndentSize:  1,
+			Prefix:      []byte{'*'},
+			Line:        []byte("\t comment\n"),
+			Rule:        RuleBlockComment,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := checkBlockComment(tc.IndentStyle, tc.IndentSize, tc.Prefix, tc.Line)
+
+			var ve ValidationError
+			if ok := errors.As(err, &ve); !ok {
+				t.Fatalf("a validation error was expected, got %v", err)
+			}
+
+			if ve.Rule != tc.Rule {
+				t.Errorf("rule %q was expected, got %q", tc.Rule, ve.Rule)
+			}
+		})
+	}
+}
+
 func TestMaxLineLength(t *testing.T) {
 	tests := []struct {
 		Name          string
@@ -317,6 +520,16 @@ func TestMaxLineLength(t *testing.T) {
 			MaxLineLength: 5,
 			TabWidth:      2,
 			Line:          []byte("\t\t.\n"),
+		}, {
+			Name:          "mid-line tab up to the tab stop",
+			MaxLineLength: 9,
+			TabWidth:      4,
+			Line:          []byte("ab\tcd\te\n"),
+		}, {
+			Name:          "spaces and tab within the same tab stop",
+			MaxLineLength: 5,
+			TabWidth:      4,
+			Line:          []byte("  \t.\n"),
 		}, {
 			Name:          "utf-8 encoded characters",
 			MaxLineLength: 1,
@@ -324,14 +537,24 @@ func TestMaxLineLength(t *testing.T) {
 			Line:          []byte("é\n"),
 		}, {
 			Name:          "utf-8 emojis",
-			MaxLineLength: 7,
+			MaxLineLength: 11,
 			TabWidth:      0,
 			Line:          []byte("🐵 🙈 🙉 🙊\r\n"),
 		}, {
 			Name:          "VMWare Inc, Globalization Team super string",
-			MaxLineLength: 17,
+			MaxLineLength: 26,
 			TabWidth:      0,
 			Line:          []byte("表ポあA鷗ŒéＢ逍Üßªąñ丂㐀𠀀\r"),
+		}, {
+			Name:          "combining accents",
+			MaxLineLength: 5,
+			TabWidth:      0,
+			Line:          []byte("e\u0301le\u0300ve\u0300\n"),
+		}, {
+			Name:          "zero width joiner",
+			MaxLineLength: 4,
+			TabWidth:      0,
+			Line:          []byte("👩\u200d👩\n"),
 		},
 	}
 	for _, tc := range tests {
@@ -363,6 +586,16 @@ func TestMaxLineLengthFailure(t *testing.T) {
 			MaxLineLength: 2,
 			TabWidth:      2,
 			Line:          []byte("\t.\r\n"),
+		}, {
+			Name:          "wide emojis",
+			MaxLineLength: 10,
+			TabWidth:      0,
+			Line:          []byte("🐵 🙈 🙉 🙊\r\n"),
+		}, {
+			Name:          "wide CJK characters",
+			MaxLineLength: 5,
+			TabWidth:      0,
+			Line:          []byte("日本語です\n"),
 		},
 	}
 	for _, tc := range tests {
@@ -376,3 +609,183 @@ func TestMaxLineLengthFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxLineLengthTabExpansion(t *testing.T) {
+	tests := []struct {
+		Name          string
+		MaxLineLength int
+		TabWidth      int
+		Line          []byte
+		Message       string
+		Position      int
+	}{
+		{
+			Name:          "leading tabs",
+			MaxLineLength: 8,
+			TabWidth:      4,
+			Line:          []byte("\t\t.\n"),
+			Message:       "line is too long (9 > 8)",
+			Position:      2,
+		}, {
+			Name:          "mid-line tab",
+			MaxLineLength: 6,
+			TabWidth:      4,
+			Line:          []byte("abc\tdef\n"),
+			Message:       "line is too long (7 > 6)",
+			Position:      6,
+		}, {
+			Name:          "tab exceeding the limit",
+			MaxLineLength: 4,
+			TabWidth:      4,
+			Line:          []byte("a\tb\n"),
+			Message:       "line is too long (5 > 4)",
+			Position:      2,
+		}, {
+			Name:          "mixed tabs and spaces",
+			MaxLineLength: 9,
+			TabWidth:      4,
+			Line:          []byte(" \t \t..\n"),
+			Message:       "line is too long (10 > 9)",
+			Position:      5,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := MaxLineLength(tc.MaxLineLength, tc.TabWidth, tc.Line)
+
+			var ve ValidationError
+			if ok := errors.As(err, &ve); !ok {
+				t.Fatalf("a validation error was expected, got %v", err)
+			}
+
+			if ve.Message != tc.Message || ve.Position != tc.Position {
+				t.Errorf("%q at %d was expected, got %q at %d", tc.Message, tc.Position, ve.Message, ve.Position)
+			}
+		})
+	}
+}
+
+func TestCheckUTF8(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Line     []byte
+		Position int
+	}{
+		{
+			Name:     "truncated multibyte sequence",
+			Line:     []byte("caf\xc3\n"),
+			Position: 3,
+		}, {
+			Name:     "truncated three bytes sequence",
+			Line:     []byte("é \xe2\x82\n"),
+			Position: 3,
+		}, {
+			Name:     "invalid continuation byte",
+			Line:     []byte("a\x80b\n"),
+			Position: 1,
+		}, {
+			Name:     "lead byte without continuation",
+			Line:     []byte("ab\xc3(\n"),
+			Position: 2,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var ve ValidationError
+			if ok := errors.As(checkUTF8(tc.Line), &ve); !ok {
+				t.Fatal("a validation error was expected")
+			}
+
+			if ve.Rule != RuleCharset || ve.Position != tc.Position {
+				t.Errorf("a charset error at %d was expected, got %s at %d", tc.Position, ve, ve.Position)
+			}
+		})
+	}
+
+	if err := checkUTF8([]byte("ÿ€ 😀\n")); err != nil {
+		t.Errorf("no errors were expected, got %s", err)
+	}
+}
+
+func TestCheckASCII(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Line     []byte
+		Position int
+	}{
+		{
+			Name:     "accented character",
+			Line:     []byte("caf\xc3\xa9\n"),
+			Position: 3,
+		}, {
+			Name:     "latin1 accented character",
+			Line:     []byte("caf\xe9\n"),
+			Position: 3,
+		}, {
+			Name:     "emoji",
+			Line:     []byte("smile \xf0\x9f\x98\x80!\n"),
+			Position: 6,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var ve ValidationError
+			if ok := errors.As(checkASCII(tc.Line), &ve); !ok {
+				t.Fatal("a validation error was expected")
+			}
+
+			if ve.Rule != RuleCharset || ve.Position != tc.Position {
+				t.Errorf("a charset error at %d was expected, got %s at %d", tc.Position, ve, ve.Position)
+			}
+		})
+	}
+
+	if err := checkASCII([]byte("plain\tascii ~\r\n")); err != nil {
+		t.Errorf("no errors were expected, got %s", err)
+	}
+}
+
+func TestCheckLatin1(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Line     []byte
+		Position int
+	}{
+		{
+			Name:     "two bytes character",
+			Line:     []byte("caf\xc3\xa9\n"),
+			Position: 3,
+		}, {
+			Name:     "three bytes character after latin1",
+			Line:     []byte("\xe9t\xe9 \xe2\x82\xac\n"),
+			Position: 4,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var ve ValidationError
+			if ok := errors.As(checkLatin1(tc.Line), &ve); !ok {
+				t.Fatal("a validation error was expected")
+			}
+
+			if ve.Rule != RuleCharset || ve.Position != tc.Position {
+				t.Errorf("a charset error at %d was expected, got %s at %d", tc.Position, ve, ve.Position)
+			}
+		})
+	}
+
+	if err := checkLatin1([]byte("caf\xe9 cr\xe8me br\xfbl\xe9e\n")); err != nil {
+		t.Errorf("no errors were expected, got %s", err)
+	}
+}